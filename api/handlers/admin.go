@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler struct {
+	logLevel *telemetry.LogLevelController
+}
+
+func NewAdminHandler(telemetryProvider telemetry.TelemetryProvider) *AdminHandler {
+	return &AdminHandler{
+		logLevel: telemetryProvider.GetLogLevel(),
+	}
+}
+
+func (h *AdminHandler) GetLogLevel(c *fiber.Ctx) error {
+	return c.JSON(h.logLevelResponse())
+}
+
+// SetLogLevel changes the log level and level overrides of every handler, optionally reverting
+// after a TTL
+func (h *AdminHandler) SetLogLevel(c *fiber.Ctx) error {
+	var req schemas.LogLevelRequest
+	ctx := c.UserContext()
+
+	if err := c.BodyParser(&req); err != nil {
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Failed to parse log level request", "error", err.Error(), "type", "parse_error")
+		return c.Status(400).JSON(schemas.ErrorResponse{
+			Error:     true,
+			Message:   "Invalid request body",
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
+		})
+	}
+
+	if req.Level == "" && req.Overrides == nil {
+		return c.Status(400).JSON(schemas.ErrorResponse{
+			Error:     true,
+			Message:   "level or overrides is required",
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
+		})
+	}
+
+	var level slog.Level
+	var err error
+	if req.Level != "" {
+		if level, err = telemetry.ParseLevel(req.Level); err != nil {
+			return c.Status(400).JSON(schemas.ErrorResponse{
+				Error:     true,
+				Message:   err.Error(),
+				TraceID:   telemetry.TraceIDFromContext(ctx),
+				RequestID: telemetry.RequestIDFromContext(ctx),
+			})
+		}
+	}
+
+	var rules []telemetry.LevelRule
+	if req.Overrides != nil {
+		if rules, err = telemetry.ParseLevelRules(*req.Overrides); err != nil {
+			return c.Status(400).JSON(schemas.ErrorResponse{
+				Error:     true,
+				Message:   err.Error(),
+				TraceID:   telemetry.TraceIDFromContext(ctx),
+				RequestID: telemetry.RequestIDFromContext(ctx),
+			})
+		}
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			return c.Status(400).JSON(schemas.ErrorResponse{
+				Error:     true,
+				Message:   "ttl must be a positive duration such as 15m",
+				TraceID:   telemetry.TraceIDFromContext(ctx),
+				RequestID: telemetry.RequestIDFromContext(ctx),
+			})
+		}
+	}
+
+	if req.Level != "" {
+		h.logLevel.Set(level, ttl)
+		telemetry.FromContext(ctx).WarnContext(ctx, "Log level changed", "level", telemetry.LevelName(level), "ttl", ttl.String())
+	}
+	if req.Overrides != nil {
+		h.logLevel.SetOverrides(rules, ttl)
+		telemetry.FromContext(ctx).WarnContext(ctx, "Log level overrides changed", "overrides", *req.Overrides, "ttl", ttl.String())
+	}
+
+	return c.JSON(h.logLevelResponse())
+}
+
+func (h *AdminHandler) logLevelResponse() schemas.LogLevelResponse {
+	level, revertAt := h.logLevel.Level()
+	rules, overridesRevertAt := h.logLevel.OverrideRules()
+	response := schemas.LogLevelResponse{
+		Level:     telemetry.LevelName(level),
+		Overrides: []string{},
+	}
+	for _, rule := range rules {
+		response.Overrides = append(response.Overrides, rule.String())
+	}
+	if !revertAt.IsZero() {
+		response.RevertAt = &revertAt
+	}
+	if !overridesRevertAt.IsZero() {
+		response.OverridesRevertAt = &overridesRevertAt
+	}
+	return response
+}
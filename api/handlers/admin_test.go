@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminApp(mockProvider *telemetry.MockTelemetryProvider) *fiber.App {
+	app := fiber.New()
+	handler := NewAdminHandler(mockProvider)
+
+	app.Get("/admin/loglevel", handler.GetLogLevel)
+	app.Put("/admin/loglevel", handler.SetLogLevel)
+	return app
+}
+
+func putLogLevel(t *testing.T, app *fiber.App, body string) (int, schemas.LogLevelResponse) {
+	req := httptest.NewRequest("PUT", "/admin/loglevel", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var response schemas.LogLevelResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return resp.StatusCode, response
+}
+
+func TestAdminHandler_GetLogLevel(t *testing.T) {
+	app := newAdminApp(telemetry.NewMockTelemetryProvider())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/admin/loglevel", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response schemas.LogLevelResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "info", response.Level)
+	assert.Nil(t, response.RevertAt)
+}
+
+func TestAdminHandler_SetLogLevel(t *testing.T) {
+	mockProvider := telemetry.NewMockTelemetryProvider()
+	app := newAdminApp(mockProvider)
+
+	status, response := putLogLevel(t, app, `{"level":"debug","ttl":"15m"}`)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "debug", response.Level)
+	assert.NotNil(t, response.RevertAt)
+
+	level, _ := mockProvider.GetLogLevel().Level()
+	assert.Equal(t, slog.LevelDebug, level)
+}
+
+func TestAdminHandler_SetLogLevel_Invalid(t *testing.T) {
+	mockProvider := telemetry.NewMockTelemetryProvider()
+	app := newAdminApp(mockProvider)
+
+	for _, body := range []string{`{}`, `{"level":"verbose"}`, `{"level":"debug","ttl":"soon"}`, `{"level":"debug","ttl":"-1m"}`} {
+		status, _ := putLogLevel(t, app, body)
+		assert.Equal(t, 400, status, body)
+	}
+
+	level, _ := mockProvider.GetLogLevel().Level()
+	assert.Equal(t, slog.LevelInfo, level)
+}
+
+func TestAdminHandler_SetLogLevel_Overrides(t *testing.T) {
+	mockProvider := telemetry.NewMockTelemetryProvider()
+	app := newAdminApp(mockProvider)
+
+	status, response := putLogLevel(t, app, `{"overrides":"route:/api/v1/cart=debug, logger:services=warn"}`)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "info", response.Level)
+	assert.Equal(t, []string{"route:/api/v1/cart=debug", "logger:services=warn"}, response.Overrides)
+
+	status, _ = putLogLevel(t, app, `{"overrides":"path:/cart=debug"}`)
+	assert.Equal(t, 400, status)
+
+	status, response = putLogLevel(t, app, `{"overrides":""}`)
+	assert.Equal(t, 200, status)
+	assert.Empty(t, response.Overrides)
+}
@@ -1,10 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"fiber-api/schemas"
 	"fiber-api/services"
 	"fiber-api/telemetry"
-	"log/slog"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go.opentelemetry.io/otel/attribute"
@@ -27,58 +28,71 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 
 	if err := c.BodyParser(&req); err != nil {
-		slog.ErrorContext(ctx, "Failed to parse cart request", "error", err.Error(), "type", "parse_error")
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Failed to parse cart request", "error", err.Error(), "type", "parse_error")
 		return c.Status(400).JSON(schemas.ErrorResponse{
-			Error:   true,
-			Message: "Invalid request body",
+			Error:     true,
+			Message:   "Invalid request body",
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
 		})
 	}
 
 	if req.UserID == "" {
-		slog.ErrorContext(ctx, "Missing user ID in cart request", "type", "validation_error")
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Missing user ID in cart request", "type", "validation_error")
 		return c.Status(400).JSON(schemas.ErrorResponse{
-			Error:   true,
-			Message: "User ID is required",
+			Error:     true,
+			Message:   "User ID is required",
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
 		})
 	}
 
+	// Every later log of this request, the service's included, carries the user ID
+	ctx = telemetry.ContextWithLogAttrs(ctx, telemetry.LogKeyUserID, req.UserID)
+	c.SetUserContext(ctx)
+
 	if len(req.Items) == 0 {
-		slog.ErrorContext(ctx, "Empty items list in cart request", "type", "validation_error")
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Empty items list in cart request", "type", "validation_error")
 		return c.Status(400).JSON(schemas.ErrorResponse{
-			Error:   true,
-			Message: "At least one item is required",
+			Error:     true,
+			Message:   "At least one item is required",
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
 		})
 	}
 
-	response, err := h.cartService.ProcessCart(c.UserContext(), req)
+	response, err := h.cartService.ProcessCart(ctx, req)
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Invalid cart request", "error", err.Error(), "type", "validation_error")
+		return c.Status(400).JSON(schemas.ErrorResponse{
+			Error:     true,
+			Message:   strings.Join(validationErr.Messages, ", "),
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
+		})
+	}
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to process cart", "error", err.Error(), "type", "processing_error")
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Failed to process cart", "error", err.Error(), "type", "processing_error")
 		return c.Status(500).JSON(schemas.ErrorResponse{
-			Error:   true,
-			Message: "Failed to process cart",
+			Error:     true,
+			Message:   "Failed to process cart",
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
 		})
 	}
 
 	// Record successful cart operation metrics
 	attributes := []attribute.KeyValue{
-		attribute.String("user_id", req.UserID),
 		attribute.Int("item_count", len(req.Items)),
 		attribute.String("status", "success"),
 	}
-	h.metricsExporter.RecordCounter(c.Context(), schemas.CartRequestsTotal, 1, attributes)
-	h.metricsExporter.RecordHistogram(c.Context(), schemas.CartItemsPerRequest, float64(len(req.Items)), attributes)
-
-	// Record gauge metrics for current cart state
-	gaugeAttributes := []attribute.KeyValue{
-		attribute.String("user_id", req.UserID),
-	}
-	h.metricsExporter.RecordGauge(c.Context(), schemas.CartCurrentValue, response.Total, gaugeAttributes)
-	h.metricsExporter.RecordGauge(c.Context(), schemas.CartCurrentItems, float64(len(req.Items)), gaugeAttributes)
+	h.metricsExporter.RecordCounter(ctx, schemas.CartRequestsTotal, 1, attributes)
+	h.metricsExporter.RecordHistogram(ctx, schemas.CartItemsPerRequest, float64(len(req.Items)), attributes)
 
 	// Log successful cart operation
-	slog.InfoContext(ctx, "Cart processed successfully",
+	telemetry.FromContext(ctx).InfoContext(ctx, "Cart processed successfully",
 		"cartId", response.ID,
-		"userId", req.UserID,
 		"itemCount", len(req.Items),
 		"total", response.Total)
 
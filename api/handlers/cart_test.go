@@ -138,3 +138,40 @@ func TestCartHandler_AddToCart_EmptyItems(t *testing.T) {
 
 	assert.Equal(t, 400, resp.StatusCode)
 }
+
+func TestCartHandler_AddToCart_InvalidItem(t *testing.T) {
+	mockProvider := telemetry.NewMockTelemetryProvider()
+
+	app := fiber.New()
+	cartService := services.NewCartService(mockProvider)
+	handler := NewCartHandler(cartService, mockProvider)
+
+	app.Post("/cart", handler.AddToCart)
+
+	cartRequest := schemas.CartRequest{
+		UserID: "user123",
+		Items: []schemas.Item{
+			{
+				ID:       "item1",
+				Name:     "Product A",
+				Price:    29.99,
+				Quantity: 0,
+			},
+		},
+	}
+
+	body, err := json.Marshal(cartRequest)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/cart", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 400, resp.StatusCode)
+
+	var errorResponse schemas.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errorResponse))
+	assert.Equal(t, "quantity is required", errorResponse.Message)
+}
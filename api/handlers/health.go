@@ -3,7 +3,6 @@ package handlers
 import (
 	"fiber-api/schemas"
 	"fiber-api/telemetry"
-	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -21,16 +20,17 @@ func NewHealthHandler(telemetryProvider telemetry.TelemetryProvider) *HealthHand
 }
 
 func (h *HealthHandler) GetHealth(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
 	// Record health check metric
 	attributes := []attribute.KeyValue{
 		attribute.String("endpoint", "health"),
 		attribute.String("status", "ok"),
 	}
-	h.metricsExporter.RecordCounter(c.Context(), schemas.HealthChecksTotal, 1, attributes)
+	h.metricsExporter.RecordCounter(ctx, schemas.HealthChecksTotal, 1, attributes)
 
 	// Log health check
-	ctx := c.UserContext()
-	slog.InfoContext(ctx, "Health check endpoint called")
+	telemetry.FromContext(ctx).InfoContext(ctx, "Health check endpoint called")
 
 	response := schemas.HealthResponse{
 		Status:    "ok",
@@ -42,21 +42,24 @@ func (h *HealthHandler) GetHealth(c *fiber.Ctx) error {
 }
 
 func (h *HealthHandler) GetError(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
 	// Record intentional error metric
 	attributes := []attribute.KeyValue{
 		attribute.String("endpoint", "error"),
 		attribute.String("type", "intentional_error"),
 	}
-	h.metricsExporter.RecordCounter(c.Context(), schemas.IntentionalErrorsTotal, 1, attributes)
+	h.metricsExporter.RecordCounter(ctx, schemas.IntentionalErrorsTotal, 1, attributes)
 
 	// Log error endpoint call
-	ctx := c.UserContext()
-	slog.ErrorContext(ctx, "Error endpoint called - this always logs as error")
+	telemetry.FromContext(ctx).ErrorContext(ctx, "Error endpoint called - this always logs as error")
 
 	response := schemas.ErrorResponse{
 		Error:     true,
 		Message:   "This endpoint always returns an error",
 		Timestamp: time.Now(),
+		TraceID:   telemetry.TraceIDFromContext(ctx),
+		RequestID: telemetry.RequestIDFromContext(ctx),
 	}
 
 	return c.Status(500).JSON(response)
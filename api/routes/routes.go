@@ -2,6 +2,8 @@ package routes
 
 import (
 	"fiber-api/api/handlers"
+	"fiber-api/config"
+	"fiber-api/middleware"
 	"fiber-api/services"
 	"fiber-api/telemetry"
 
@@ -13,10 +15,22 @@ func SetupRoutes(app *fiber.App, telemetryProvider telemetry.TelemetryProvider)
 
 	healthHandler := handlers.NewHealthHandler(telemetryProvider)
 	cartHandler := handlers.NewCartHandler(cartService, telemetryProvider)
+	adminHandler := handlers.NewAdminHandler(telemetryProvider)
 
 	api := app.Group("/api/v1")
 
 	api.Get("/health", healthHandler.GetHealth)
 	api.Get("/error", healthHandler.GetError)
 	api.Post("/cart", cartHandler.AddToCart)
+
+	// Admin routes change how the service logs, so they are only served with ADMIN_TOKEN set,
+	// to callers presenting it
+	adminToken := config.GetConfig().AdminToken
+	if adminToken == "" {
+		return
+	}
+	admin := app.Group("/admin", middleware.AdminAuth(adminToken))
+
+	admin.Get("/loglevel", adminHandler.GetLogLevel)
+	admin.Put("/loglevel", adminHandler.SetLogLevel)
 }
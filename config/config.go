@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -14,8 +16,71 @@ type Config struct {
 	LogLevel     string
 	OTLPEndpoint string
 	OtelAPIKey   string
+	Propagators  string
+	AdminToken   string
+
+	LogLevelOverrides string
+	LogFormat         string
+	LogSource         bool
+
+	LogOTLPAsync       bool
+	LogAsyncBufferSize int
+	LogAsyncDropPolicy string
+
+	LogSamplingEnabled           bool
+	LogSamplingWindow            time.Duration
+	LogSamplingFirst             int
+	LogSamplingThereafter        int
+	LogSamplingKeepSampledTraces bool
+
+	LogFilePath       string
+	LogFileFormat     string
+	LogFileMaxSizeMB  int
+	LogFileMaxAge     time.Duration
+	LogFileMaxBackups int
+	LogFileCompress   bool
+
+	AccessLogEnabled   bool
+	AccessLogFormat    string
+	AccessLogOutput    string
+	AccessLogSkipPaths string
+
+	RuntimeMetricsEnabled bool
+	SpanMetricsEnabled    bool
+
+	MetricExportInterval       time.Duration
+	MetricExportTimeout        time.Duration
+	MetricTemporality          string
+	MetricTemporalityOverrides string
+	MetricExemplarFilter       string
+
+	CartAbandonedAfter time.Duration
+	CartMaxOpen        int
+
+	BaggageAllowedKeys     string
+	BaggageMetricKeys      string
+	BaggageMetricMaxValues int
+
+	RedactionRules string
+	RedactionSalt  string
+
+	BodyCaptureEnabled      bool
+	BodyCaptureContentTypes string
+	BodyCaptureMaxBytes     int
+	BodyCaptureSampleRatio  float64
+	BodyCaptureRedactFields string
 }
 
+// DefaultRedactionRules keep user IDs, client addresses, full user agents and query strings
+// from leaving the process. Besides the current semantic convention keys they cover the older
+// ones otelfiber sets on server spans (http.user_agent, http.client_ip, enduser.id, and
+// http.target and http.url, which carry the query string) and url.full on client spans.
+// User IDs are dropped rather than hashed, since hashing needs REDACTION_SALT.
+const DefaultRedactionRules = "user_id=drop,userId=drop," +
+	"user_agent.original=truncate:32,http.user_agent=truncate:32,user_agent=truncate:32," +
+	"client.address=drop,http.client_ip=drop,ip=drop,enduser.id=drop,url.query=drop," +
+	"http.target=strip_query,http.url=strip_query,url.full=strip_query"
+
 func LoadConfig() *Config {
 	viper.SetConfigFile(".env")
 	viper.ReadInConfig()
@@ -26,8 +91,50 @@ func LoadConfig() *Config {
 	viper.SetDefault("PORT", "3000")
 	viper.SetDefault("ENV", "development")
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_LEVEL_OVERRIDES", "")
+	viper.SetDefault("LOG_FORMAT", "text")
+	viper.SetDefault("LOG_SOURCE", false)
+	viper.SetDefault("LOG_OTLP_ASYNC", false)
+	viper.SetDefault("LOG_ASYNC_BUFFER_SIZE", 1024)
+	viper.SetDefault("LOG_ASYNC_DROP_POLICY", "drop_newest")
+	viper.SetDefault("LOG_SAMPLING_ENABLED", true)
+	viper.SetDefault("LOG_SAMPLING_WINDOW", time.Second)
+	viper.SetDefault("LOG_SAMPLING_FIRST", 10)
+	viper.SetDefault("LOG_SAMPLING_THEREAFTER", 100)
+	viper.SetDefault("LOG_SAMPLING_KEEP_SAMPLED_TRACES", true)
+	viper.SetDefault("LOG_FILE_PATH", "")
+	viper.SetDefault("LOG_FILE_FORMAT", "json")
+	viper.SetDefault("LOG_FILE_MAX_SIZE_MB", 100)
+	viper.SetDefault("LOG_FILE_MAX_AGE", 24*time.Hour)
+	viper.SetDefault("LOG_FILE_MAX_BACKUPS", 7)
+	viper.SetDefault("LOG_FILE_COMPRESS", true)
+	viper.SetDefault("ACCESS_LOG_ENABLED", true)
+	viper.SetDefault("ACCESS_LOG_FORMAT", "combined")
+	viper.SetDefault("ACCESS_LOG_OUTPUT", "stdout")
+	viper.SetDefault("ACCESS_LOG_SKIP_PATHS", "/api/v1/health")
 	viper.SetDefault("OTLP_ENDPOINT", "")
 	viper.SetDefault("OTEL_API_KEY", "")
+	viper.SetDefault("OTEL_PROPAGATORS", "tracecontext,baggage")
+	viper.SetDefault("ADMIN_TOKEN", "")
+	viper.SetDefault("RUNTIME_METRICS_ENABLED", true)
+	viper.SetDefault("SPAN_METRICS_ENABLED", true)
+	viper.SetDefault("METRIC_EXPORT_INTERVAL", defaultMetricExportInterval(viper.GetString("ENV")))
+	viper.SetDefault("METRIC_EXPORT_TIMEOUT", 30*time.Second)
+	viper.SetDefault("METRIC_TEMPORALITY", "cumulative")
+	viper.SetDefault("METRIC_TEMPORALITY_OVERRIDES", "")
+	viper.SetDefault("METRIC_EXEMPLAR_FILTER", "trace_based")
+	viper.SetDefault("CART_ABANDONED_AFTER", 30*time.Minute)
+	viper.SetDefault("CART_MAX_OPEN", 10000)
+	viper.SetDefault("BAGGAGE_ALLOWED_KEYS", "")
+	viper.SetDefault("BAGGAGE_METRIC_KEYS", "")
+	viper.SetDefault("BAGGAGE_METRIC_MAX_VALUES", 50)
+	viper.SetDefault("REDACTION_RULES", DefaultRedactionRules)
+	viper.SetDefault("REDACTION_SALT", "")
+	viper.SetDefault("BODY_CAPTURE_ENABLED", false)
+	viper.SetDefault("BODY_CAPTURE_CONTENT_TYPES", "application/json")
+	viper.SetDefault("BODY_CAPTURE_MAX_BYTES", 4096)
+	viper.SetDefault("BODY_CAPTURE_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("BODY_CAPTURE_REDACT_FIELDS", "password,token,cardNumber")
 
 	cfg = &Config{
 		Port:         viper.GetString("PORT"),
@@ -35,11 +142,74 @@ func LoadConfig() *Config {
 		LogLevel:     viper.GetString("LOG_LEVEL"),
 		OTLPEndpoint: viper.GetString("OTLP_ENDPOINT"),
 		OtelAPIKey:   viper.GetString("OTEL_API_KEY"),
+		Propagators:  viper.GetString("OTEL_PROPAGATORS"),
+		AdminToken:   viper.GetString("ADMIN_TOKEN"),
+
+		LogLevelOverrides: viper.GetString("LOG_LEVEL_OVERRIDES"),
+		LogFormat:         viper.GetString("LOG_FORMAT"),
+		LogSource:         viper.GetBool("LOG_SOURCE"),
+
+		LogOTLPAsync:       viper.GetBool("LOG_OTLP_ASYNC"),
+		LogAsyncBufferSize: viper.GetInt("LOG_ASYNC_BUFFER_SIZE"),
+		LogAsyncDropPolicy: viper.GetString("LOG_ASYNC_DROP_POLICY"),
+
+		LogSamplingEnabled:           viper.GetBool("LOG_SAMPLING_ENABLED"),
+		LogSamplingWindow:            viper.GetDuration("LOG_SAMPLING_WINDOW"),
+		LogSamplingFirst:             viper.GetInt("LOG_SAMPLING_FIRST"),
+		LogSamplingThereafter:        viper.GetInt("LOG_SAMPLING_THEREAFTER"),
+		LogSamplingKeepSampledTraces: viper.GetBool("LOG_SAMPLING_KEEP_SAMPLED_TRACES"),
+
+		LogFilePath:       viper.GetString("LOG_FILE_PATH"),
+		LogFileFormat:     viper.GetString("LOG_FILE_FORMAT"),
+		LogFileMaxSizeMB:  viper.GetInt("LOG_FILE_MAX_SIZE_MB"),
+		LogFileMaxAge:     viper.GetDuration("LOG_FILE_MAX_AGE"),
+		LogFileMaxBackups: viper.GetInt("LOG_FILE_MAX_BACKUPS"),
+		LogFileCompress:   viper.GetBool("LOG_FILE_COMPRESS"),
+
+		AccessLogEnabled:   viper.GetBool("ACCESS_LOG_ENABLED"),
+		AccessLogFormat:    viper.GetString("ACCESS_LOG_FORMAT"),
+		AccessLogOutput:    viper.GetString("ACCESS_LOG_OUTPUT"),
+		AccessLogSkipPaths: viper.GetString("ACCESS_LOG_SKIP_PATHS"),
+
+		RuntimeMetricsEnabled: viper.GetBool("RUNTIME_METRICS_ENABLED"),
+		SpanMetricsEnabled:    viper.GetBool("SPAN_METRICS_ENABLED"),
+
+		MetricExportInterval:       viper.GetDuration("METRIC_EXPORT_INTERVAL"),
+		MetricExportTimeout:        viper.GetDuration("METRIC_EXPORT_TIMEOUT"),
+		MetricTemporality:          viper.GetString("METRIC_TEMPORALITY"),
+		MetricTemporalityOverrides: viper.GetString("METRIC_TEMPORALITY_OVERRIDES"),
+		MetricExemplarFilter:       viper.GetString("METRIC_EXEMPLAR_FILTER"),
+
+		CartAbandonedAfter: viper.GetDuration("CART_ABANDONED_AFTER"),
+		CartMaxOpen:        viper.GetInt("CART_MAX_OPEN"),
+
+		BaggageAllowedKeys:     viper.GetString("BAGGAGE_ALLOWED_KEYS"),
+		BaggageMetricKeys:      viper.GetString("BAGGAGE_METRIC_KEYS"),
+		BaggageMetricMaxValues: viper.GetInt("BAGGAGE_METRIC_MAX_VALUES"),
+
+		RedactionRules: viper.GetString("REDACTION_RULES"),
+		RedactionSalt:  viper.GetString("REDACTION_SALT"),
+
+		BodyCaptureEnabled:      viper.GetBool("BODY_CAPTURE_ENABLED"),
+		BodyCaptureContentTypes: viper.GetString("BODY_CAPTURE_CONTENT_TYPES"),
+		BodyCaptureMaxBytes:     viper.GetInt("BODY_CAPTURE_MAX_BYTES"),
+		BodyCaptureSampleRatio:  viper.GetFloat64("BODY_CAPTURE_SAMPLE_RATIO"),
+		BodyCaptureRedactFields: viper.GetString("BODY_CAPTURE_REDACT_FIELDS"),
 	}
 
 	return cfg
 }
 
+// defaultMetricExportInterval keeps local feedback fast without flooding the backend elsewhere
+func defaultMetricExportInterval(environment string) time.Duration {
+	switch environment {
+	case "development", "dev", "local":
+		return 5 * time.Second
+	default:
+		return 60 * time.Second
+	}
+}
+
 func GetConfig() *Config {
 	if cfg == nil {
 		LoadConfig()
@@ -0,0 +1,229 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config controls timeouts and retries for outbound calls
+type Config struct {
+	// PeerService names the downstream service, e.g. "inventory", for spans and metrics
+	PeerService string
+	// Timeout bounds a single attempt, from dialing until the response body is read, so the
+	// caller must finish reading the body within it
+	Timeout time.Duration
+	// MaxRetries is the number of additional attempts after the first one
+	MaxRetries int
+	// RetryBackoff is the initial delay between attempts, doubled after each retry
+	RetryBackoff time.Duration
+	// Transport defaults to http.DefaultTransport
+	Transport http.RoundTripper
+	// Propagator defaults to the global propagator set up by the telemetry provider
+	Propagator propagation.TextMapPropagator
+}
+
+func DefaultConfig(peerService string) Config {
+	return Config{
+		PeerService:  peerService,
+		Timeout:      5 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: 100 * time.Millisecond,
+	}
+}
+
+// Client is an instrumented net/http client that creates a client span per attempt,
+// propagates the trace context and records client-side request metrics
+type Client struct {
+	httpClient      *http.Client
+	config          Config
+	tracesExporter  telemetry.TracesExporter
+	metricsExporter telemetry.MetricsExporter
+}
+
+func NewClient(telemetryProvider telemetry.TelemetryProvider, cfg Config) *Client {
+	transport := cfg.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Client{
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+		},
+		config:          cfg,
+		tracesExporter:  telemetryProvider.GetTracesExporter(),
+		metricsExporter: telemetryProvider.GetMetricsExporter(),
+	}
+}
+
+// Get issues a GET request carrying the trace context of ctx
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends the request, retrying idempotent requests on transport errors and retryable
+// status codes. Requests with a body are only retried when req.GetBody is set.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := c.config.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req, attempt)
+
+		if attempt >= c.config.MaxRetries || !c.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		// Drain the discarded response so the connection can be reused
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	start := time.Now()
+
+	spanAttrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(req.URL.Redacted()),
+		semconv.ServerAddress(req.URL.Hostname()),
+		semconv.PeerService(c.config.PeerService),
+	}
+	if port, err := strconv.Atoi(req.URL.Port()); err == nil {
+		spanAttrs = append(spanAttrs, semconv.ServerPort(port))
+	}
+	if attempt > 0 {
+		spanAttrs = append(spanAttrs, semconv.HTTPResendCount(attempt))
+	}
+
+	ctx, span := c.tracesExporter.StartSpan(ctx, req.Method,
+		telemetry.WithSpanKind(trace.SpanKindClient),
+		telemetry.WithAttributes(spanAttrs...),
+	)
+	defer span.End()
+
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	c.propagator().Inject(ctx, propagation.HeaderCarrier(attemptReq.Header))
+
+	resp, err := c.httpClient.Do(attemptReq)
+
+	metricAttrs := []attribute.KeyValue{
+		attribute.String("peer_service", c.config.PeerService),
+		attribute.String("method", req.Method),
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		// status stays an int on every series; a transport error has no status, only a type
+		metricAttrs = append(metricAttrs, attribute.String("error.type", errorType(err)))
+		c.recordMetrics(ctx, start, metricAttrs)
+		c.metricsExporter.RecordCounter(ctx, schemas.ErrorsTotal, 1, append(metricAttrs, attribute.String("type", "client_error")))
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	}
+
+	metricAttrs = append(metricAttrs, attribute.Int("status", resp.StatusCode))
+	c.recordMetrics(ctx, start, metricAttrs)
+
+	return resp, nil
+}
+
+// errorType classifies a transport error into a low-cardinality error.type value
+func errorType(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "_OTHER"
+	}
+}
+
+func (c *Client) recordMetrics(ctx context.Context, start time.Time, attributes []attribute.KeyValue) {
+	c.metricsExporter.RecordCounter(ctx, schemas.HTTPClientRequestsTotal, 1, attributes)
+	c.metricsExporter.RecordHistogram(ctx, schemas.HTTPClientDurationSeconds, time.Since(start).Seconds(), attributes)
+}
+
+func (c *Client) propagator() propagation.TextMapPropagator {
+	if c.config.Propagator != nil {
+		return c.config.Propagator
+	}
+	return otel.GetTextMapPropagator()
+}
+
+func (c *Client) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if !isIdempotent(req.Method) {
+		return false
+	}
+
+	if err != nil {
+		// Do not retry once the caller has given up
+		return !errors.Is(err, context.Canceled) && req.Context().Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fiber-api/telemetry"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracingTelemetryProvider struct {
+	*telemetry.MockTelemetryProvider
+	tracesExporter telemetry.TracesExporter
+}
+
+func (p *tracingTelemetryProvider) GetTracesExporter() telemetry.TracesExporter {
+	return p.tracesExporter
+}
+
+func newTestClient(t *testing.T, maxRetries int) (*Client, *tracetest.SpanRecorder, trace.Tracer) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+	tracer := tracerProvider.Tracer("test")
+
+	provider := &tracingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		tracesExporter:        telemetry.NewTracesExporter(tracer),
+	}
+
+	cfg := DefaultConfig("inventory")
+	cfg.MaxRetries = maxRetries
+	cfg.RetryBackoff = time.Millisecond
+	cfg.Propagator = propagation.TraceContext{}
+
+	return NewClient(provider, cfg), recorder, tracer
+}
+
+func TestClient_PropagatesTraceContext(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, recorder, tracer := newTestClient(t, 0)
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	resp, err := client.Get(ctx, server.URL+"/stock")
+	parent.End()
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	clientSpan := spans[0]
+
+	assert.Equal(t, "GET", clientSpan.Name())
+	assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Contains(t, traceparent, clientSpan.SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, clientSpan.SpanContext().SpanID().String())
+}
+
+func TestClient_RetriesRetryableStatus(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, recorder, _ := newTestClient(t, 2)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, server.URL, bytes.NewReader([]byte(`{"sku":"1"}`)))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Len(t, recorder.Ended(), 3)
+}
+
+func TestClient_DoesNotRetryNonIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, _, _ := newTestClient(t, 2)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, bytes.NewReader([]byte(`{}`)))
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestErrorType(t *testing.T) {
+	client, _, _ := newTestClient(t, 0)
+	client.httpClient.Timeout = 10 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, err := client.Get(context.Background(), server.URL)
+	require.Error(t, err)
+	assert.Equal(t, "timeout", errorType(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Get(ctx, server.URL)
+	require.Error(t, err)
+	assert.Equal(t, "canceled", errorType(err))
+
+	_, err = client.Get(context.Background(), "http://127.0.0.1:1")
+	require.Error(t, err)
+	assert.Equal(t, "_OTHER", errorType(err))
+}
@@ -37,6 +37,7 @@ func main() {
 		ErrorHandler: middleware.ErrorHandler(telemetryProvider),
 	})
 
+	// Last-resort recovery for panics in middleware; handler panics are recorded by Recover below
 	app.Use(recover.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
@@ -44,6 +45,18 @@ func main() {
 		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
 	}))
 
+	// Write the access log ahead of otelfiber, so it sees the final response of every request
+	var accessLogger *middleware.AccessLogger
+	if cfg.AccessLogEnabled {
+		accessLogger, err = middleware.NewAccessLogger(cfg, telemetryProvider.GetRedactor())
+		if err != nil {
+			slog.Error("Failed to create access log", "error", err)
+			os.Exit(1)
+		}
+		defer accessLogger.Close()
+		app.Use(accessLogger.Handler())
+	}
+
 	// Configure otelfiber with our tracer and meter providers
 	app.Use(otelfiber.Middleware(
 		otelfiber.WithServerName("fiber-api"),
@@ -51,12 +64,27 @@ func main() {
 		otelfiber.WithMeterProvider(otel.GetMeterProvider()),
 	))
 
+	// Return the trace ID on every response so customers can quote it
+	app.Use(middleware.TraceHeaders())
+
+	// Accept or generate X-Request-ID for the span, logs, error bodies and response headers
+	app.Use(middleware.RequestID(telemetryProvider))
+
+	// Give handlers and services a logger carrying the route, request ID and trace ID
+	app.Use(middleware.ContextLogger(telemetryProvider))
+
+	// Make allow-listed W3C baggage available to spans, logs and metrics
+	app.Use(middleware.Baggage(telemetryProvider))
+
 	// Add detailed tracing middleware for granular HTTP spans
 	app.Use(middleware.DetailedTracing(telemetryProvider))
 
 	// Add our custom logger middleware for HTTP request logging and metrics
 	app.Use(middleware.Logger(telemetryProvider))
 
+	// Record handler panics on the span, in logs and as metrics, and answer with a 500
+	app.Use(middleware.Recover(telemetryProvider))
+
 	routes.SetupRoutes(app, telemetryProvider)
 
 	go func() {
@@ -66,6 +94,46 @@ func main() {
 		}
 	}()
 
+	// Re-read LOG_LEVEL and LOG_LEVEL_OVERRIDES on SIGHUP so they can be changed without a restart
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			reloaded := config.LoadConfig()
+			level, err := telemetry.ParseLevel(reloaded.LogLevel)
+			if err != nil {
+				slog.Error("Failed to reload log level", "error", err)
+				continue
+			}
+			rules, err := telemetry.ParseLevelRules(reloaded.LogLevelOverrides)
+			if err != nil {
+				slog.Error("Failed to reload log level overrides", "error", err)
+				continue
+			}
+			telemetryProvider.GetLogLevel().Reset(level, rules)
+			slog.Info("Log level reloaded", "level", telemetry.LevelName(level), "overrides", len(rules))
+		}
+	}()
+
+	// Reopen the log files on SIGUSR1, after logrotate has moved them away
+	reopen := make(chan os.Signal, 1)
+	signal.Notify(reopen, syscall.SIGUSR1)
+	go func() {
+		for range reopen {
+			if err := telemetryProvider.ReopenLogs(); err != nil {
+				slog.Error("Failed to reopen log file", "error", err)
+				continue
+			}
+			if accessLogger != nil {
+				if err := accessLogger.Reopen(); err != nil {
+					slog.Error("Failed to reopen access log", "error", err)
+					continue
+				}
+			}
+			slog.Info("Log files reopened")
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
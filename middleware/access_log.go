@@ -0,0 +1,378 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fiber-api/config"
+	"fiber-api/telemetry"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Access log formats selected by ACCESS_LOG_FORMAT
+const (
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+	AccessLogLogfmt   = "logfmt"
+)
+
+// clfTimeFormat is the timestamp format of the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogger writes one line per request to its own destination, separate from the
+// application logs. Lines in the common and combined formats are followed by the request ID,
+// trace ID, request bytes and latency in microseconds, as in the Apache format
+// `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i" "%{X-Request-ID}o" "%{X-Trace-Id}o" %I %D`.
+//
+// The client address, user agent, referer and query string go through the redactor under the
+// keys client.address, user_agent.original, http.request.header.referer and url.query, so
+// REDACTION_RULES covers the access log as well.
+type AccessLogger struct {
+	format   string
+	skip     []string
+	redactor *telemetry.Redactor
+
+	mu     sync.Mutex
+	output io.Writer
+	file   *telemetry.RotatingFile
+}
+
+// NewAccessLogger creates the access logger configured by the ACCESS_LOG_* settings. An output
+// other than stdout or stderr is a file path, rotated with the LOG_FILE_* settings.
+func NewAccessLogger(cfg *config.Config, redactor *telemetry.Redactor) (*AccessLogger, error) {
+	var file *telemetry.RotatingFile
+	var output io.Writer
+	switch cfg.AccessLogOutput {
+	case "", "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	default:
+		var err error
+		file, err = telemetry.NewRotatingFile(telemetry.RotatingFileOptions{
+			Path:       cfg.AccessLogOutput,
+			MaxSize:    int64(cfg.LogFileMaxSizeMB) << 20,
+			MaxAge:     cfg.LogFileMaxAge,
+			MaxBackups: cfg.LogFileMaxBackups,
+			Compress:   cfg.LogFileCompress,
+		})
+		if err != nil {
+			return nil, err
+		}
+		output = file
+	}
+
+	accessLogger, err := newAccessLogger(cfg.AccessLogFormat, output, cfg.AccessLogSkipPaths, redactor)
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, err
+	}
+	accessLogger.file = file
+	return accessLogger, nil
+}
+
+func newAccessLogger(format string, output io.Writer, skipPaths string, redactor *telemetry.Redactor) (*AccessLogger, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = AccessLogCombined
+	case AccessLogCommon, AccessLogCombined, AccessLogJSON, AccessLogLogfmt:
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", format)
+	}
+
+	var skip []string
+	for _, path := range strings.Split(skipPaths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			skip = append(skip, path)
+		}
+	}
+	return &AccessLogger{format: format, skip: skip, redactor: redactor, output: output}, nil
+}
+
+// Handler returns the access log middleware. Register it ahead of otelfiber, so the logged
+// status and size are those of the final response, including error responses; the trace ID is
+// taken from the X-Trace-Id response header set by TraceHeaders.
+func (l *AccessLogger) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if l.skipped(c.Path()) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			// Render the error response now, as fiber's own logger does, so it is what gets logged
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		l.write(accessLogEntry{
+			time:       start,
+			remoteAddr: l.redact("client.address", c.IP()),
+			method:     c.Method(),
+			uri:        l.redactURI(c.OriginalURL()),
+			protocol:   string(c.Request().Header.Protocol()),
+			status:     c.Response().StatusCode(),
+			bytesIn:    len(c.Request().Body()),
+			bytesOut:   len(c.Response().Body()),
+			referer:    l.redact("http.request.header.referer", c.Get(fiber.HeaderReferer)),
+			userAgent:  l.redact("user_agent.original", c.Get(fiber.HeaderUserAgent)),
+			requestID:  c.GetRespHeader(HeaderRequestID),
+			traceID:    c.GetRespHeader(HeaderTraceID),
+			latency:    time.Since(start),
+		})
+		return nil
+	}
+}
+
+// redact applies the redaction rules for key to a field; a dropped field is logged as empty
+func (l *AccessLogger) redact(key, value string) string {
+	if value == "" {
+		return ""
+	}
+	value, keep := l.redactor.Value(key, value)
+	if !keep {
+		return ""
+	}
+	return value
+}
+
+// redactURI applies the url.query rules to the query string of a request URI
+func (l *AccessLogger) redactURI(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+	if query = l.redact("url.query", query); query == "" {
+		return path
+	}
+	return path + "?" + query
+}
+
+// Reopen reopens the access log file, e.g. after logrotate moved it away; it is a no-op for
+// stdout and stderr
+func (l *AccessLogger) Reopen() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Reopen()
+}
+
+// Close closes the access log file, if any
+func (l *AccessLogger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// skipped reports whether a path matches a skip rule; a trailing * matches any suffix
+func (l *AccessLogger) skipped(path string) bool {
+	for _, rule := range l.skip {
+		if prefix, ok := strings.CutSuffix(rule, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == rule {
+			return true
+		}
+	}
+	return false
+}
+
+type accessLogEntry struct {
+	time       time.Time
+	remoteAddr string
+	method     string
+	uri        string
+	protocol   string
+	status     int
+	bytesIn    int
+	bytesOut   int
+	referer    string
+	userAgent  string
+	requestID  string
+	traceID    string
+	latency    time.Duration
+}
+
+func (l *AccessLogger) write(entry accessLogEntry) {
+	var buf bytes.Buffer
+	switch l.format {
+	case AccessLogJSON:
+		appendAccessLogJSON(&buf, entry)
+	case AccessLogLogfmt:
+		appendAccessLogLogfmt(&buf, entry)
+	default:
+		appendAccessLogCLF(&buf, entry, l.format == AccessLogCombined)
+	}
+	buf.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A failed access log write must not fail the request; the file sink reports its own errors
+	_, _ = l.output.Write(buf.Bytes())
+}
+
+func appendAccessLogCLF(buf *bytes.Buffer, entry accessLogEntry, combined bool) {
+	buf.WriteString(clfField(entry.remoteAddr))
+	buf.WriteString(" - - [")
+	buf.WriteString(entry.time.Format(clfTimeFormat))
+	buf.WriteString("] ")
+	buf.WriteString(clfQuote(entry.method + " " + entry.uri + " " + entry.protocol))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(entry.status))
+	buf.WriteByte(' ')
+	buf.WriteString(clfBytes(entry.bytesOut))
+	if combined {
+		buf.WriteByte(' ')
+		buf.WriteString(clfQuote(entry.referer))
+		buf.WriteByte(' ')
+		buf.WriteString(clfQuote(entry.userAgent))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(clfQuote(entry.requestID))
+	buf.WriteByte(' ')
+	buf.WriteString(clfQuote(entry.traceID))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(entry.bytesIn))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(entry.latency.Microseconds(), 10))
+}
+
+// clfField returns - for an empty field, as the Common Log Format does
+func clfField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func clfBytes(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
+
+// clfQuote quotes a field the way Apache does, escaping quotes, backslashes and control bytes
+func clfQuote(value string) string {
+	if value == "" {
+		return `"-"`
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// accessLogRecord is the JSON form of an entry, with field names matching the console JSON logs
+type accessLogRecord struct {
+	Time       string  `json:"ts"`
+	RemoteAddr string  `json:"remote_addr"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Protocol   string  `json:"protocol"`
+	Status     int     `json:"status"`
+	BytesIn    int     `json:"bytes_in"`
+	BytesOut   int     `json:"bytes_out"`
+	LatencyMS  float64 `json:"latency_ms"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+	RequestID  string  `json:"request_id,omitempty"`
+	TraceID    string  `json:"trace_id,omitempty"`
+}
+
+func appendAccessLogJSON(buf *bytes.Buffer, entry accessLogEntry) {
+	record := accessLogRecord{
+		Time:       entry.time.UTC().Format(time.RFC3339Nano),
+		RemoteAddr: entry.remoteAddr,
+		Method:     entry.method,
+		URI:        entry.uri,
+		Protocol:   entry.protocol,
+		Status:     entry.status,
+		BytesIn:    entry.bytesIn,
+		BytesOut:   entry.bytesOut,
+		LatencyMS:  latencyMilliseconds(entry.latency),
+		Referer:    entry.referer,
+		UserAgent:  entry.userAgent,
+		RequestID:  entry.requestID,
+		TraceID:    entry.traceID,
+	}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(record)
+	// Encode ends the value with a newline; write adds its own
+	buf.Truncate(buf.Len() - 1)
+}
+
+func appendAccessLogLogfmt(buf *bytes.Buffer, entry accessLogEntry) {
+	pairs := []struct{ key, value string }{
+		{"ts", entry.time.UTC().Format(time.RFC3339Nano)},
+		{"remote_addr", entry.remoteAddr},
+		{"method", entry.method},
+		{"uri", entry.uri},
+		{"protocol", entry.protocol},
+		{"status", strconv.Itoa(entry.status)},
+		{"bytes_in", strconv.Itoa(entry.bytesIn)},
+		{"bytes_out", strconv.Itoa(entry.bytesOut)},
+		{"latency_ms", strconv.FormatFloat(latencyMilliseconds(entry.latency), 'f', -1, 64)},
+		{"referer", entry.referer},
+		{"user_agent", entry.userAgent},
+		{"request_id", entry.requestID},
+		{"trace_id", entry.traceID},
+	}
+	for i, pair := range pairs {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(pair.key)
+		buf.WriteByte('=')
+		if logfmtNeedsQuoting(pair.value) {
+			buf.WriteString(strconv.Quote(pair.value))
+		} else {
+			buf.WriteString(pair.value)
+		}
+	}
+}
+
+// logfmtNeedsQuoting reports whether a value is empty or holds a space, quote, equals sign,
+// backslash or control byte, any of which would break the key=value framing
+func logfmtNeedsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c <= ' ' || c == 0x7f || c == '"' || c == '=' || c == '\\' {
+			return true
+		}
+	}
+	return false
+}
+
+// latencyMilliseconds rounds a latency to microsecond precision
+func latencyMilliseconds(latency time.Duration) float64 {
+	return float64(latency.Microseconds()) / 1000
+}
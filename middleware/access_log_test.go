@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"fiber-api/config"
+	"fiber-api/telemetry"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessLogApp(t *testing.T, format, skipPaths string) (*fiber.App, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	accessLogger, err := newAccessLogger(format, &buf, skipPaths, nil)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(accessLogger.Handler())
+	app.Get("/api/v1/health", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Post("/api/v1/cart", func(c *fiber.Ctx) error {
+		c.Set(HeaderRequestID, "req-1")
+		c.Set(HeaderTraceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+		return c.Status(fiber.StatusCreated).SendString("created")
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusNotFound, "no such thing")
+	})
+	return app, &buf
+}
+
+func sendAccessLogRequest(t *testing.T, app *fiber.App) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/v1/cart?x=1", strings.NewReader(`{"a":1}`))
+	req.Header.Set(fiber.HeaderUserAgent, `curl/8.0 "test"`)
+	req.Header.Set(fiber.HeaderReferer, "https://example.com/")
+	_, err := app.Test(req, -1)
+	require.NoError(t, err)
+}
+
+func TestAccessLog_Combined(t *testing.T) {
+	app, buf := newAccessLogApp(t, AccessLogCombined, "")
+	sendAccessLogRequest(t, app)
+
+	pattern := regexp.MustCompile(`^0\.0\.0\.0 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] ` +
+		`"POST /api/v1/cart\?x=1 HTTP/1\.1" 201 7 "https://example\.com/" "curl/8\.0 \\"test\\"" ` +
+		`"req-1" "4bf92f3577b34da6a3ce929d0e0e4736" 7 \d+\n$`)
+	assert.Regexp(t, pattern, buf.String())
+}
+
+func TestAccessLog_Common(t *testing.T) {
+	app, buf := newAccessLogApp(t, AccessLogCommon, "")
+	sendAccessLogRequest(t, app)
+
+	assert.Contains(t, buf.String(), `"POST /api/v1/cart?x=1 HTTP/1.1" 201 7 "req-1" "4bf92f3577b34da6a3ce929d0e0e4736" 7 `)
+	assert.NotContains(t, buf.String(), "curl")
+}
+
+func TestAccessLog_JSON(t *testing.T) {
+	app, buf := newAccessLogApp(t, AccessLogJSON, "")
+	sendAccessLogRequest(t, app)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "POST", record["method"])
+	assert.Equal(t, "/api/v1/cart?x=1", record["uri"])
+	assert.Equal(t, float64(201), record["status"])
+	assert.Equal(t, float64(7), record["bytes_in"])
+	assert.Equal(t, float64(7), record["bytes_out"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Contains(t, record, "latency_ms")
+	assert.Contains(t, record, "ts")
+}
+
+func TestAccessLog_Logfmt(t *testing.T) {
+	app, buf := newAccessLogApp(t, AccessLogLogfmt, "")
+	sendAccessLogRequest(t, app)
+
+	line := buf.String()
+	assert.Contains(t, line, " method=POST uri=\"/api/v1/cart?x=1\" protocol=HTTP/1.1 status=201 bytes_in=7 bytes_out=7 ")
+	assert.Contains(t, line, `user_agent="curl/8.0 \"test\""`)
+	assert.Contains(t, line, "request_id=req-1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736\n")
+}
+
+func TestAccessLog_LogfmtQuotesControlBytes(t *testing.T) {
+	var buf bytes.Buffer
+	appendAccessLogLogfmt(&buf, accessLogEntry{userAgent: "evil\x1b[31m\x7f", referer: "a\rb"})
+
+	line := buf.String()
+	assert.Contains(t, line, `user_agent="evil\x1b[31m\x7f"`)
+	assert.Contains(t, line, `referer="a\rb"`)
+	assert.NotContains(t, line, "\x1b")
+}
+
+func TestAccessLog_LogsErrorResponses(t *testing.T) {
+	app, buf := newAccessLogApp(t, AccessLogLogfmt, "")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/missing", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, buf.String(), "status=404")
+}
+
+func TestAccessLog_SkipPaths(t *testing.T) {
+	app, buf := newAccessLogApp(t, AccessLogCombined, "/api/v1/health, /internal/*")
+
+	_, err := app.Test(httptest.NewRequest("GET", "/api/v1/health", nil), -1)
+	require.NoError(t, err)
+	_, err = app.Test(httptest.NewRequest("GET", "/internal/metrics", nil), -1)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	_, err = app.Test(httptest.NewRequest("GET", "/missing", nil), -1)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"GET /missing HTTP/1.1" 404`)
+}
+
+func TestAccessLog_RedactsClientFields(t *testing.T) {
+	rules, err := telemetry.ParseRedactionRules(config.DefaultRedactionRules)
+	require.NoError(t, err)
+	redactor, err := telemetry.NewRedactor(rules, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	accessLogger, err := newAccessLogger(AccessLogJSON, &buf, "", redactor)
+	require.NoError(t, err)
+	app := fiber.New()
+	app.Use(accessLogger.Handler())
+	app.Get("/api/v1/cart", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/cart?token=secret", nil)
+	req.Header.Set(fiber.HeaderUserAgent, "Mozilla/5.0 (X11; Linux x86_64) Gecko/20100101 Firefox/120.0")
+	_, err = app.Test(req, -1)
+	require.NoError(t, err)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "", record["remote_addr"])
+	assert.Equal(t, "/api/v1/cart", record["uri"])
+	assert.Equal(t, "Mozilla/5.0 (X11; Linux x86_64) ", record["user_agent"])
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestAccessLog_UnknownFormat(t *testing.T) {
+	_, err := newAccessLogger("xml", &bytes.Buffer{}, "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// activeRequestsTracker keeps a concurrency-safe in-flight count per route
+type activeRequestsTracker struct {
+	routes sync.Map // route -> *atomic.Int64
+}
+
+func newActiveRequestsTracker() *activeRequestsTracker {
+	return &activeRequestsTracker{}
+}
+
+func (t *activeRequestsTracker) counter(route string) *atomic.Int64 {
+	if v, ok := t.routes.Load(route); ok {
+		return v.(*atomic.Int64)
+	}
+	v, _ := t.routes.LoadOrStore(route, new(atomic.Int64))
+	return v.(*atomic.Int64)
+}
+
+func (t *activeRequestsTracker) inc(route string) int64 {
+	return t.counter(route).Add(1)
+}
+
+func (t *activeRequestsTracker) dec(route string) int64 {
+	return t.counter(route).Add(-1)
+}
+
+// value returns the current number of in-flight requests for a route
+func (t *activeRequestsTracker) value(route string) int64 {
+	if v, ok := t.routes.Load(route); ok {
+		return v.(*atomic.Int64).Load()
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminAuth middleware requires the admin token as an "Authorization: Bearer <token>" header,
+// answering 401 with the standard error body otherwise
+func AdminAuth(token string) fiber.Handler {
+	expected := []byte(token)
+
+	return func(c *fiber.Ctx) error {
+		provided, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			ctx := c.UserContext()
+			telemetry.FromContext(ctx).WarnContext(ctx, "Rejected admin request", "path", c.Path(), "type", "auth_error")
+			return c.Status(fiber.StatusUnauthorized).JSON(schemas.ErrorResponse{
+				Error:     true,
+				Message:   "Unauthorized",
+				Timestamp: time.Now(),
+				TraceID:   telemetry.TraceIDFromContext(ctx),
+				RequestID: telemetry.RequestIDFromContext(ctx),
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAuth(t *testing.T) {
+	app := fiber.New()
+	app.Use(AdminAuth("s3cret"))
+	app.Get("/admin/loglevel", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for header, status := range map[string]int{
+		"":              fiber.StatusUnauthorized,
+		"s3cret":        fiber.StatusUnauthorized,
+		"Bearer wrong":  fiber.StatusUnauthorized,
+		"Bearer s3cre":  fiber.StatusUnauthorized,
+		"Bearer s3cret": fiber.StatusOK,
+	} {
+		req := httptest.NewRequest("GET", "/admin/loglevel", nil)
+		if header != "" {
+			req.Header.Set(fiber.HeaderAuthorization, header)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode, "Authorization %q", header)
+	}
+}
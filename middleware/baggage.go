@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fiber-api/config"
+	"fiber-api/telemetry"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Baggage middleware tags the active server span with the allow-listed baggage members. It
+// only sees the baggage extracted by otelfiber, so OTEL_PROPAGATORS must include "baggage".
+// Spans started later are enriched by the BaggageSpanProcessor and logs by the BaggageHandler.
+func Baggage(telemetryProvider telemetry.TelemetryProvider) fiber.Handler {
+	tracesExporter := telemetryProvider.GetTracesExporter()
+	filter := telemetry.NewBaggageFilter(config.GetConfig().BaggageAllowedKeys)
+
+	return func(c *fiber.Ctx) error {
+		if filter.Empty() {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		if attributes := filter.Attributes(ctx); len(attributes) > 0 {
+			tracesExporter.SetAttributes(ctx, attributes)
+		}
+
+		return c.Next()
+	}
+}
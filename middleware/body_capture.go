@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fiber-api/config"
+	"fiber-api/telemetry"
+	"math/rand/v2"
+	"mime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const redactedBodyValue = "[REDACTED]"
+
+// unredactableBodyValue replaces bodies that cannot be parsed for redaction, such as malformed
+// JSON, since they may hold the very fields redaction is configured for
+const unredactableBodyValue = "[unredactable body omitted]"
+
+// bodyCapture decides which request and response bodies are recorded on spans and prepares
+// them for recording: redacting JSON fields first and truncating the result second. Fields are
+// replaced when listed in BODY_CAPTURE_REDACT_FIELDS and otherwise go through the REDACTION_RULES
+// applied to every other telemetry output, matched against the field name.
+type bodyCapture struct {
+	enabled      bool
+	contentTypes map[string]bool
+	maxBytes     int
+	sampleRatio  float64
+	redactFields map[string]bool
+	redactor     *telemetry.Redactor
+}
+
+func newBodyCapture(cfg *config.Config, redactor *telemetry.Redactor) *bodyCapture {
+	return &bodyCapture{
+		enabled:      cfg.BodyCaptureEnabled,
+		contentTypes: splitSet(cfg.BodyCaptureContentTypes),
+		maxBytes:     cfg.BodyCaptureMaxBytes,
+		sampleRatio:  cfg.BodyCaptureSampleRatio,
+		redactFields: splitSet(cfg.BodyCaptureRedactFields),
+		redactor:     redactor,
+	}
+}
+
+// splitSet lower-cases a comma-separated list into a lookup set
+func splitSet(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+// sampled makes the per-request capture decision
+func (b *bodyCapture) sampled() bool {
+	if !b.enabled || b.sampleRatio <= 0 {
+		return false
+	}
+	return b.sampleRatio >= 1 || rand.Float64() < b.sampleRatio
+}
+
+func (b *bodyCapture) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return b.contentTypes[strings.ToLower(mediaType)]
+}
+
+// attributes returns the event attributes for a body, or nil when it must not be captured
+func (b *bodyCapture) attributes(prefix, contentType string, body []byte) []attribute.KeyValue {
+	if len(body) == 0 || !b.allowed(contentType) {
+		return nil
+	}
+
+	content := b.redact(body)
+	truncated := false
+	if b.maxBytes > 0 && len(content) > b.maxBytes {
+		content = content[:b.maxBytes]
+		// Avoid splitting a multi-byte character at the cut
+		for len(content) > 0 && !utf8.ValidString(content) {
+			content = content[:len(content)-1]
+		}
+		truncated = true
+	}
+
+	return []attribute.KeyValue{
+		attribute.String(prefix+".content", content),
+		attribute.Int(prefix+".size", len(body)),
+		attribute.Bool(prefix+".truncated", truncated),
+	}
+}
+
+// redact replaces configured JSON fields at any depth. With redact fields or rules configured,
+// bodies that are not valid JSON are omitted, as their fields cannot be found.
+func (b *bodyCapture) redact(body []byte) string {
+	if len(b.redactFields) == 0 && b.redactor.Empty() {
+		return string(body)
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return unredactableBodyValue
+	}
+
+	redacted, err := json.Marshal(b.redactValue(document))
+	if err != nil {
+		return unredactableBodyValue
+	}
+	return string(redacted)
+}
+
+func (b *bodyCapture) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if b.redactFields[strings.ToLower(key)] {
+				v[key] = redactedBodyValue
+				continue
+			}
+			value, keep := b.redactScalar(key, child)
+			if !keep {
+				delete(v, key)
+				continue
+			}
+			v[key] = b.redactValue(value)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = b.redactValue(child)
+		}
+	}
+	return value
+}
+
+// redactScalar applies the redaction rule matching key to a string, number or boolean, keeping
+// the value's JSON type unless the rule rewrites it; objects and arrays are returned as is
+func (b *bodyCapture) redactScalar(key string, value any) (any, bool) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	default:
+		return value, true
+	}
+
+	redacted, keep := b.redactor.Value(key, text)
+	if !keep || redacted == text {
+		return value, keep
+	}
+	return redacted, true
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"fiber-api/config"
+	"fiber-api/telemetry"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func newTestBodyCapture(t *testing.T, maxBytes int, sampleRatio float64) *bodyCapture {
+	rules, err := telemetry.ParseRedactionRules(config.DefaultRedactionRules)
+	require.NoError(t, err)
+	redactor, err := telemetry.NewRedactor(rules, "")
+	require.NoError(t, err)
+
+	return newBodyCapture(&config.Config{
+		BodyCaptureEnabled:      true,
+		BodyCaptureContentTypes: "application/json, text/plain",
+		BodyCaptureMaxBytes:     maxBytes,
+		BodyCaptureSampleRatio:  sampleRatio,
+		BodyCaptureRedactFields: "password,token,cardNumber",
+	}, redactor)
+}
+
+func eventAttribute(t *testing.T, span sdktrace.ReadOnlySpan, eventName string, key attribute.Key) attribute.Value {
+	t.Helper()
+	for _, event := range span.Events() {
+		if event.Name != eventName {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == key {
+				return attr.Value
+			}
+		}
+	}
+	require.Failf(t, "missing event attribute", "%s %s", eventName, key)
+	return attribute.Value{}
+}
+
+func TestBodyCapture_Attributes(t *testing.T) {
+	capture := newTestBodyCapture(t, 4096, 1)
+
+	attributes := capture.attributes("http.request.body", "application/json; charset=utf-8",
+		[]byte(`{"userId":"u1","Password":"hunter2","payment":{"cardNumber":"4111"},"items":[{"token":"abc","user_id":7,"quantity":2}]}`))
+	require.Len(t, attributes, 3)
+	content := attributes[0].Value.AsString()
+	assert.NotContains(t, content, "hunter2")
+	assert.NotContains(t, content, "4111")
+	assert.NotContains(t, content, "abc")
+	assert.NotContains(t, content, "userId", "fields matching the redaction rules must be dropped")
+	assert.NotContains(t, content, "user_id")
+	assert.Contains(t, content, `"quantity":2`)
+	assert.Contains(t, content, redactedBodyValue)
+	assert.Equal(t, attribute.Bool("http.request.body.truncated", false), attributes[2])
+
+	// Bodies that cannot be parsed for redaction are omitted, malformed JSON included
+	attributes = capture.attributes("http.request.body", "text/plain", []byte("password=hunter2"))
+	assert.Equal(t, attribute.String("http.request.body.content", unredactableBodyValue), attributes[0])
+	attributes = capture.attributes("http.request.body", "application/json", []byte(`{"password":"hunter2"`))
+	assert.Equal(t, attribute.String("http.request.body.content", unredactableBodyValue), attributes[0])
+
+	// Without redact fields or rules, non-JSON bodies of allowed types are kept as is
+	capture.redactFields = map[string]bool{}
+	capture.redactor = nil
+	attributes = capture.attributes("http.request.body", "text/plain", []byte("plain text"))
+	assert.Equal(t, attribute.String("http.request.body.content", "plain text"), attributes[0])
+
+	assert.Nil(t, capture.attributes("http.request.body", "image/png", []byte{0x89}))
+	assert.Nil(t, capture.attributes("http.request.body", "application/json", nil))
+}
+
+func TestBodyCapture_Truncation(t *testing.T) {
+	capture := newTestBodyCapture(t, 5, 1)
+	capture.redactFields = map[string]bool{}
+	capture.redactor = nil
+
+	attributes := capture.attributes("http.response.body", "text/plain", []byte("héllo world"))
+	require.Len(t, attributes, 3)
+	assert.Equal(t, attribute.String("http.response.body.content", "héll"), attributes[0])
+	assert.Equal(t, attribute.Int("http.response.body.size", len("héllo world")), attributes[1])
+	assert.Equal(t, attribute.Bool("http.response.body.truncated", true), attributes[2])
+}
+
+func TestBodyCapture_Sampling(t *testing.T) {
+	assert.True(t, newTestBodyCapture(t, 10, 1).sampled())
+	assert.False(t, newTestBodyCapture(t, 10, 0).sampled())
+	assert.False(t, (&bodyCapture{sampleRatio: 1}).sampled(), "disabled capture is never sampled")
+}
+
+func TestDetailedTracing_CapturesBodies(t *testing.T) {
+	app, recorder := newCaptureTracingApp(t, newTestBodyCapture(t, 4096, 1))
+	app.Post("/login", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"token": "secret-token", "user": "u1"})
+	})
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"user":"u1","password":"hunter2"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "server")
+
+	request := eventAttribute(t, spans["server"], eventRequestBody, "http.request.body.content").AsString()
+	assert.Contains(t, request, `"user":"u1"`)
+	assert.NotContains(t, request, "hunter2")
+
+	response := eventAttribute(t, spans["server"], eventResponseBody, "http.response.body.content").AsString()
+	assert.Contains(t, response, `"user":"u1"`)
+	assert.NotContains(t, response, "secret-token")
+}
+
+func TestDetailedTracing_BodyCaptureDisabled(t *testing.T) {
+	app, recorder := newTracingApp(t)
+	app.Post("/login", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user": "u1"})
+	})
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"user":"u1"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	for _, span := range recorder.Ended() {
+		assert.Empty(t, span.Events(), span.Name())
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fiber-api/telemetry"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextLogger middleware stores a request-scoped logger in the user context, derived from the
+// provider's logger with the route template, request ID and trace ID, for handlers and services
+// to get through telemetry.FromContext. Handlers add the user ID once they know it. It must be
+// registered after otelfiber and RequestID so the span and request ID are in the user context.
+func ContextLogger(telemetryProvider telemetry.TelemetryProvider) fiber.Handler {
+	logger := telemetryProvider.GetLogger()
+	routes := newRouteTemplates()
+
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		args := []any{telemetry.LogKeyRoute, routes.match(c)}
+		if requestID := telemetry.RequestIDFromContext(ctx); requestID != "" {
+			args = append(args, telemetry.LogKeyRequestID, requestID)
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			args = append(args, telemetry.LogKeyTraceID, spanContext.TraceID().String())
+		}
+
+		c.SetUserContext(telemetry.ContextWithLogger(ctx, logger.With(args...)))
+		return c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fiber-api/telemetry"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type loggingTelemetryProvider struct {
+	*telemetry.MockTelemetryProvider
+	logger *slog.Logger
+}
+
+func (p *loggingTelemetryProvider) GetLogger() *slog.Logger {
+	return p.logger
+}
+
+func TestContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	provider := &loggingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		logger:                slog.New(telemetry.NewRequestIDHandler(telemetry.NewTraceContextHandler(slog.NewTextHandler(&buf, nil)))),
+	}
+	traceID := trace.TraceID{0x0a, 0x0b}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  trace.SpanID{0x01},
+		})))
+		return c.Next()
+	})
+	app.Use(RequestID(provider))
+	app.Use(ContextLogger(provider))
+	app.Get("/cart/:id", func(c *fiber.Ctx) error {
+		ctx := telemetry.ContextWithLogAttrs(c.UserContext(), telemetry.LogKeyUserID, "user123")
+		telemetry.FromContext(ctx).InfoContext(ctx, "cart loaded")
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/cart/42", nil)
+	req.Header.Set(HeaderRequestID, "req-1")
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `msg="cart loaded" route=/cart/:id request_id=req-1 trace_id=`+traceID.String()+" user_id=user123")
+	assert.Equal(t, 1, strings.Count(output, "request_id="))
+	assert.Equal(t, 1, strings.Count(output, "trace_id="))
+}
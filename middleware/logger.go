@@ -2,82 +2,99 @@ package middleware
 
 import (
 	"errors"
+	"fiber-api/config"
 	"fiber-api/schemas"
 	"fiber-api/telemetry"
-	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// Logger middleware records HTTP request metrics. It also logs each request through slog,
+// unless ACCESS_LOG_ENABLED hands request logging to the access log.
 func Logger(telemetryProvider telemetry.TelemetryProvider) fiber.Handler {
+	return logger(telemetryProvider, !config.GetConfig().AccessLogEnabled)
+}
+
+func logger(telemetryProvider telemetry.TelemetryProvider, logRequests bool) fiber.Handler {
 	metricsExporter := telemetryProvider.GetMetricsExporter()
 
-	// Track active requests count (gauge metric)
-	var activeRequests int64 = 0
+	// Track in-flight requests per route (up-down counter metric)
+	activeRequests := newActiveRequestsTracker()
+	routes := newRouteTemplates()
 
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
 
-		// Increment active requests gauge
-		activeRequests++
-		gaugeAttrs := []attribute.KeyValue{
-			attribute.String("endpoint", c.Path()),
+		// Label metrics with the route template rather than the raw path, which would give
+		// every distinct URL its own series
+		endpoint := routes.match(c)
+
+		// The user context carries the request span, which links metric exemplars to traces,
+		// and the route, which selects per-route log level overrides
+		ctx := telemetry.ContextWithRoute(c.UserContext(), endpoint)
+		c.SetUserContext(ctx)
+		inFlight := activeRequests.inc(endpoint)
+		activeAttrs := []attribute.KeyValue{
+			attribute.String("endpoint", endpoint),
 		}
 
-		metricsExporter.RecordGauge(c.Context(), schemas.HTTPActiveRequests, float64(activeRequests), gaugeAttrs)
+		metricsExporter.RecordUpDownCounter(ctx, schemas.HTTPActiveRequests, 1, activeAttrs)
+		// Deferred so a panic escaping the handlers still releases the in-flight count
+		defer func() {
+			activeRequests.dec(endpoint)
+			metricsExporter.RecordUpDownCounter(ctx, schemas.HTTPActiveRequests, -1, activeAttrs)
+		}()
 
 		err := c.Next()
 
-		// Decrement active requests gauge
-		activeRequests--
-		metricsExporter.RecordGauge(c.Context(), schemas.HTTPActiveRequests, float64(activeRequests), gaugeAttrs)
-
 		duration := time.Since(start)
 		status := c.Response().StatusCode()
 
 		// Record HTTP request metrics
 		attributes := []attribute.KeyValue{
 			attribute.String("method", c.Method()),
-			attribute.String("path", c.Path()),
+			attribute.String("path", endpoint),
 			attribute.Int("status", status),
 		}
-		metricsExporter.RecordCounter(c.Context(), schemas.HTTPRequestsTotal, 1, attributes)
+		metricsExporter.RecordCounter(ctx, schemas.HTTPRequestsTotal, 1, attributes)
 
-		metricsExporter.RecordHistogram(c.Context(), schemas.HTTPRequestDurationSeconds, duration.Seconds(), attributes)
+		metricsExporter.RecordHistogram(ctx, schemas.HTTPRequestDurationSeconds, duration.Seconds(), attributes)
 
 		// Record error metrics if applicable
 		if status >= 400 {
 			errorAttrs := []attribute.KeyValue{
 				attribute.String("method", c.Method()),
-				attribute.String("path", c.Path()),
+				attribute.String("path", endpoint),
 				attribute.Int("status", status),
 				attribute.String("type", "http_error"),
 			}
-			metricsExporter.RecordCounter(c.Context(), schemas.ErrorsTotal, 1, errorAttrs)
+			metricsExporter.RecordCounter(ctx, schemas.ErrorsTotal, 1, errorAttrs)
 		}
 
 		// Log HTTP request with trace context
-		ctx := c.UserContext()
-		slog.InfoContext(ctx, "HTTP Request",
-			"method", c.Method(),
-			"path", c.Path(),
-			"status", status,
-			"duration", duration.String(),
-			"ip", c.IP(),
-			"user_agent", c.Get("User-Agent"),
-		)
+		if logRequests {
+			telemetry.FromContext(ctx).InfoContext(ctx, "HTTP Request",
+				"method", c.Method(),
+				"path", c.Path(),
+				"status", status,
+				"duration", duration.String(),
+				"ip", c.IP(),
+				"user_agent", c.Get("User-Agent"),
+				"active_requests", inFlight,
+			)
+		}
 
 		if err != nil {
 			// Record middleware error metrics
 			errorAttrs := []attribute.KeyValue{
 				attribute.String("method", c.Method()),
-				attribute.String("path", c.Path()),
+				attribute.String("path", endpoint),
 				attribute.Int("status", status),
 				attribute.String("type", "middleware_error"),
 			}
-			metricsExporter.RecordCounter(c.Context(), schemas.ErrorsTotal, 1, errorAttrs)
+			metricsExporter.RecordCounter(ctx, schemas.ErrorsTotal, 1, errorAttrs)
 
 		}
 
@@ -96,27 +113,34 @@ func ErrorHandler(telemetryProvider telemetry.TelemetryProvider) func(*fiber.Ctx
 			code = e.Code
 		}
 
+		ctx := c.UserContext()
+		route := requestRoute(c)
+
 		// Record application error metrics
 		attributes := []attribute.KeyValue{
 			attribute.String("method", c.Method()),
-			attribute.String("path", c.Path()),
+			attribute.String("path", route),
 			attribute.Int("status", code),
 			attribute.String("type", "application_error"),
 		}
-		metricsExporter.RecordCounter(c.Context(), schemas.ErrorsTotal, 1, attributes)
+		metricsExporter.RecordCounter(ctx, schemas.ErrorsTotal, 1, attributes)
 
-		// Log application error with trace context
-		ctx := c.UserContext()
-		slog.ErrorContext(ctx, "Request error: "+err.Error(),
+		// Log application error with trace context; the message is static so log sampling
+		// counts every occurrence of the error together
+		telemetry.FromContext(ctx).ErrorContext(ctx, "Request error",
+			"error", err.Error(),
 			"method", c.Method(),
 			"path", c.Path(),
 			"status", code,
 			"ip", c.IP(),
 		)
 
-		return c.Status(code).JSON(fiber.Map{
-			"error":   true,
-			"message": err.Error(),
+		return c.Status(code).JSON(schemas.ErrorResponse{
+			Error:     true,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			TraceID:   telemetry.TraceIDFromContext(ctx),
+			RequestID: telemetry.RequestIDFromContext(ctx),
 		})
 	}
 }
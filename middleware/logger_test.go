@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// recordingMetricsExporter sums up-down counter deltas per endpoint
+type recordingMetricsExporter struct {
+	telemetry.MockMetricsExporter
+	mu      sync.Mutex
+	active  map[string]int64
+	maxSeen int64
+}
+
+func (r *recordingMetricsExporter) RecordUpDownCounter(ctx context.Context, name string, value int64, attributes []attribute.KeyValue) {
+	if name != schemas.HTTPActiveRequests {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, attr := range attributes {
+		if attr.Key == "endpoint" {
+			r.active[attr.Value.AsString()] += value
+			if r.active[attr.Value.AsString()] > r.maxSeen {
+				r.maxSeen = r.active[attr.Value.AsString()]
+			}
+		}
+	}
+}
+
+type recordingTelemetryProvider struct {
+	*telemetry.MockTelemetryProvider
+	metricsExporter *recordingMetricsExporter
+}
+
+func (p *recordingTelemetryProvider) GetMetricsExporter() telemetry.MetricsExporter {
+	return p.metricsExporter
+}
+
+func TestLogger_ActiveRequestsConcurrent(t *testing.T) {
+	exporter := &recordingMetricsExporter{active: map[string]int64{}}
+	provider := &recordingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		metricsExporter:       exporter,
+	}
+
+	app := fiber.New()
+	app.Use(Logger(provider))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		time.Sleep(5 * time.Millisecond)
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/fast", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	const workers = 50
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := "/fast"
+			if i%2 == 0 {
+				path = "/slow"
+			}
+			resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+			if assert.NoError(t, err) {
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	assert.Equal(t, int64(0), exporter.active["/slow"])
+	assert.Equal(t, int64(0), exporter.active["/fast"])
+	assert.Positive(t, exporter.maxSeen)
+}
+
+func TestLogger_ActiveRequestsByRouteTemplate(t *testing.T) {
+	exporter := &recordingMetricsExporter{active: map[string]int64{}}
+	provider := &recordingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		metricsExporter:       exporter,
+	}
+
+	app := fiber.New()
+	app.Use(Logger(provider))
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for _, path := range []string{"/items/1", "/items/2", "/other/3"} {
+		_, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		assert.NoError(t, err)
+	}
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	assert.Len(t, exporter.active, 2)
+	assert.Contains(t, exporter.active, "/items/:id")
+	assert.Contains(t, exporter.active, unmatchedRoute)
+}
+
+func TestActiveRequestsTracker(t *testing.T) {
+	tracker := newActiveRequestsTracker()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.inc("/api/v1/cart")
+			tracker.dec("/api/v1/cart")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(0), tracker.value("/api/v1/cart"))
+	assert.Equal(t, int64(1), tracker.inc("/api/v1/health"))
+	assert.Equal(t, int64(0), tracker.value("/unknown"))
+}
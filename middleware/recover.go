@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Recover middleware turns a panic into a 500 with the standard error body, recording the panic
+// value and stack as an exception event on the active span, an ERROR log with the stack and an
+// ErrorsTotal increment with type=panic. Register it after the other middleware, just before the
+// routes, so they see an ordinary 500 response: their spans end and their metrics are recorded.
+func Recover(telemetryProvider telemetry.TelemetryProvider) fiber.Handler {
+	metricsExporter := telemetryProvider.GetMetricsExporter()
+	tracesExporter := telemetryProvider.GetTracesExporter()
+
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			ctx := c.UserContext()
+			route := requestRoute(c)
+			stack := string(debug.Stack())
+			panicErr, ok := recovered.(error)
+			if ok {
+				panicErr = fmt.Errorf("panic: %w", panicErr)
+			} else {
+				panicErr = fmt.Errorf("panic: %v", recovered)
+			}
+
+			tracesExporter.RecordError(ctx, panicErr, []attribute.KeyValue{
+				semconv.ExceptionStacktrace(stack),
+			})
+			tracesExporter.SetStatus(ctx, codes.Error, panicErr.Error())
+
+			metricsExporter.RecordCounter(ctx, schemas.ErrorsTotal, 1, []attribute.KeyValue{
+				attribute.String("method", c.Method()),
+				attribute.String("path", route),
+				attribute.Int("status", fiber.StatusInternalServerError),
+				attribute.String("type", "panic"),
+			})
+
+			telemetry.FromContext(ctx).ErrorContext(ctx, "Panic recovered",
+				"error", panicErr.Error(),
+				"stack", stack,
+				"method", c.Method(),
+				"path", route,
+				"type", "panic",
+			)
+
+			// The panic value may hold internals, so the body carries a generic message
+			err = c.Status(fiber.StatusInternalServerError).JSON(schemas.ErrorResponse{
+				Error:     true,
+				Message:   "Internal Server Error",
+				Timestamp: time.Now(),
+				TraceID:   telemetry.TraceIDFromContext(ctx),
+				RequestID: telemetry.RequestIDFromContext(ctx),
+			})
+		}()
+
+		return c.Next()
+	}
+}
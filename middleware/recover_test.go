@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"log/slog"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// counterMetricsExporter keeps the attributes of every counter increment by name
+type counterMetricsExporter struct {
+	telemetry.MockMetricsExporter
+	mu       sync.Mutex
+	counters map[string][][]attribute.KeyValue
+}
+
+func (e *counterMetricsExporter) RecordCounter(ctx context.Context, name string, value int64, attributes []attribute.KeyValue) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counters[name] = append(e.counters[name], attributes)
+}
+
+type recoverTelemetryProvider struct {
+	*telemetry.MockTelemetryProvider
+	metricsExporter telemetry.MetricsExporter
+	tracesExporter  telemetry.TracesExporter
+}
+
+func (p *recoverTelemetryProvider) GetMetricsExporter() telemetry.MetricsExporter {
+	return p.metricsExporter
+}
+
+func (p *recoverTelemetryProvider) GetTracesExporter() telemetry.TracesExporter {
+	return p.tracesExporter
+}
+
+func TestRecover(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+	tracer := tracerProvider.Tracer("test")
+
+	metrics := &counterMetricsExporter{counters: map[string][][]attribute.KeyValue{}}
+	provider := &recoverTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		metricsExporter:       metrics,
+		tracesExporter:        telemetry.NewTracesExporter(tracer),
+	}
+	var logs bytes.Buffer
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		ctx, span := tracer.Start(c.UserContext(), "server", trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		ctx = telemetry.ContextWithRequestID(ctx, "req-1")
+		// As stored by Logger
+		ctx = telemetry.ContextWithRoute(ctx, "/panic/:id")
+		c.SetUserContext(telemetry.ContextWithLogger(ctx, slog.New(slog.NewJSONHandler(&logs, nil))))
+		return c.Next()
+	})
+	app.Use(Recover(provider))
+	app.Get("/panic/:id", func(c *fiber.Ctx) error {
+		panic(errors.New("nil map"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/panic/42", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	var body schemas.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.True(t, body.Error)
+	assert.Equal(t, "Internal Server Error", body.Message)
+	assert.Equal(t, "req-1", body.RequestID)
+	assert.NotEmpty(t, body.TraceID)
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "server")
+	server := spans["server"]
+	assert.Equal(t, codes.Error, server.Status().Code)
+	require.Len(t, server.Events(), 1)
+	event := server.Events()[0]
+	assert.Equal(t, semconv.ExceptionEventName, event.Name)
+	assert.Contains(t, event.Attributes, semconv.ExceptionMessage("panic: nil map"))
+	var stack string
+	for _, attr := range event.Attributes {
+		if attr.Key == semconv.ExceptionStacktraceKey {
+			stack = attr.Value.AsString()
+		}
+	}
+	assert.Contains(t, stack, "middleware.TestRecover")
+
+	require.Len(t, metrics.counters[schemas.ErrorsTotal], 1)
+	assert.Contains(t, metrics.counters[schemas.ErrorsTotal][0], attribute.String("type", "panic"))
+	assert.Contains(t, metrics.counters[schemas.ErrorsTotal][0], attribute.String("path", "/panic/:id"),
+		"the path must be the route template, not the raw path")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "Panic recovered", record["msg"])
+	assert.Equal(t, "panic: nil map", record["error"])
+	assert.Equal(t, "/panic/:id", record["path"])
+	assert.Contains(t, record["stack"], "middleware.TestRecover")
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	app := fiber.New()
+	app.Use(Recover(telemetry.NewMockTelemetryProvider()))
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ok", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
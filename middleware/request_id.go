@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fiber-api/telemetry"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// HeaderRequestID is the header carrying the request ID
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds client supplied request IDs, which end up in every log record
+const maxRequestIDLength = 128
+
+// RequestID middleware accepts the X-Request-ID request header or generates a UUID when it is
+// missing or malformed. The ID is stored in the user context, where the RequestIDHandler adds
+// it to log records, echoed as a response header and set on the server span. It must be
+// registered after otelfiber so the server span is in the user context.
+func RequestID(telemetryProvider telemetry.TelemetryProvider) fiber.Handler {
+	tracesExporter := telemetryProvider.GetTracesExporter()
+
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if validRequestID(requestID) {
+			// Fiber reuses request buffers and the ID outlives the request in async log handlers
+			requestID = utils.CopyString(requestID)
+		} else {
+			requestID = uuid.NewString()
+		}
+
+		ctx := telemetry.ContextWithRequestID(c.UserContext(), requestID)
+		c.SetUserContext(ctx)
+		c.Set(HeaderRequestID, requestID)
+		tracesExporter.SetAttributes(ctx, []attribute.KeyValue{
+			attribute.String(telemetry.RequestIDKey, requestID),
+		})
+
+		return c.Next()
+	}
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so they are safe in headers
+// and every log format
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if c := requestID[i]; c <= ' ' || c > '~' || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
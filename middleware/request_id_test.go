@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRequestIDApp mimics otelfiber by starting a server span before RequestID runs
+func newRequestIDApp(t *testing.T) (*fiber.App, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+	tracer := tracerProvider.Tracer("test")
+
+	provider := &tracingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		tracesExporter:        telemetry.NewTracesExporter(tracer),
+	}
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: ErrorHandler(provider),
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		ctx, span := tracer.Start(c.UserContext(), "server", trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		c.SetUserContext(ctx)
+		return c.Next()
+	})
+	app.Use(RequestID(provider))
+	app.Get("/id", func(c *fiber.Ctx) error {
+		return c.SendString(telemetry.RequestIDFromContext(c.UserContext()))
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+	return app, recorder
+}
+
+func TestRequestID_AcceptsHeader(t *testing.T) {
+	app, recorder := newRequestIDApp(t)
+
+	req := httptest.NewRequest("GET", "/id", nil)
+	req.Header.Set(HeaderRequestID, "client-id-42")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "client-id-42", resp.Header.Get(HeaderRequestID))
+	body := make([]byte, 64)
+	n, _ := resp.Body.Read(body)
+	assert.Equal(t, "client-id-42", string(body[:n]))
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "server")
+	assert.Contains(t, spans["server"].Attributes(), attribute.String(telemetry.RequestIDKey, "client-id-42"))
+}
+
+func TestRequestID_GeneratesWhenMissingOrInvalid(t *testing.T) {
+	app, _ := newRequestIDApp(t)
+
+	for _, header := range []string{"", "has space", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest("GET", "/id", nil)
+		if header != "" {
+			req.Header.Set(HeaderRequestID, header)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		_, err = uuid.Parse(resp.Header.Get(HeaderRequestID))
+		assert.NoError(t, err, "header %q", header)
+	}
+}
+
+func TestRequestID_InErrorResponse(t *testing.T) {
+	app, _ := newRequestIDApp(t)
+
+	req := httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set(HeaderRequestID, "client-id-42")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var body schemas.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "client-id-42", body.RequestID)
+	assert.NotEmpty(t, body.TraceID)
+}
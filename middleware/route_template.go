@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"fiber-api/telemetry"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests that match no registered route, keeping per-route metrics
+// bounded by the routes of the app rather than by the paths clients send
+const unmatchedRoute = "unmatched"
+
+// routeTemplates resolves a request to the template of the route it will be served by, e.g.
+// /api/v1/items/:id. Middleware runs before fiber's router picks the route, so c.Route() does
+// not name it yet; the routes are read from the app on the first request, once all are mounted.
+type routeTemplates struct {
+	once   sync.Once
+	routes map[string][]routeTemplate // method -> templates, in registration order
+}
+
+type routeTemplate struct {
+	path     string
+	segments []string
+}
+
+func newRouteTemplates() *routeTemplates {
+	return &routeTemplates{}
+}
+
+// match returns the template of the route serving c, or unmatchedRoute
+func (t *routeTemplates) match(c *fiber.Ctx) string {
+	t.once.Do(func() {
+		t.routes = make(map[string][]routeTemplate)
+		for _, route := range c.App().GetRoutes(true) {
+			t.routes[route.Method] = append(t.routes[route.Method], routeTemplate{
+				path:     route.Path,
+				segments: splitRoutePath(route.Path),
+			})
+		}
+	})
+
+	segments := splitRoutePath(c.Path())
+	for _, route := range t.routes[c.Method()] {
+		if route.matches(segments) {
+			return route.path
+		}
+	}
+	return unmatchedRoute
+}
+
+// matches follows fiber's default routing: case-insensitive, ignoring a trailing slash, with
+// :param segments (optional when suffixed with ?) and * or + wildcards matching the rest
+func (r routeTemplate) matches(segments []string) bool {
+	for i, pattern := range r.segments {
+		switch {
+		case strings.HasPrefix(pattern, "*"):
+			return true
+		case strings.HasPrefix(pattern, "+"):
+			return i < len(segments)
+		case i >= len(segments):
+			return strings.HasPrefix(pattern, ":") && strings.HasSuffix(pattern, "?") && i == len(r.segments)-1
+		case strings.ContainsAny(pattern, ":*+"):
+			// A parameter, alone or mixed with static text such as :from-:to
+		case !strings.EqualFold(pattern, segments[i]):
+			return false
+		}
+	}
+	return len(r.segments) == len(segments)
+}
+
+func splitRoutePath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// requestRoute returns the route template Logger stored in the user context, falling back to
+// fiber's route, which names the middleware rather than a route on a 404
+func requestRoute(c *fiber.Ctx) string {
+	if route := telemetry.RouteFromContext(c.UserContext()); route != "" {
+		return route
+	}
+	return c.Route().Path
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteTemplates_Match(t *testing.T) {
+	routes := newRouteTemplates()
+	var matched string
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		matched = routes.match(c)
+		return c.Next()
+	})
+	noop := func(c *fiber.Ctx) error { return nil }
+	app.Get("/", noop)
+	app.Get("/api/v1/health", noop)
+	app.Get("/api/v1/items/:id", noop)
+	app.Get("/api/v1/users/:id?", noop)
+	app.Get("/flights/:from-:to", noop)
+	app.Get("/static/*", noop)
+	app.Post("/api/v1/cart", noop)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"GET", "/", "/"},
+		{"GET", "/api/v1/health", "/api/v1/health"},
+		{"GET", "/API/v1/Health/", "/api/v1/health"},
+		{"GET", "/api/v1/items/42", "/api/v1/items/:id"},
+		{"GET", "/api/v1/items/42/extra", unmatchedRoute},
+		{"GET", "/api/v1/users", "/api/v1/users/:id?"},
+		{"GET", "/api/v1/users/7", "/api/v1/users/:id?"},
+		{"GET", "/flights/lax-sfo", "/flights/:from-:to"},
+		{"GET", "/static/css/site.css", "/static/*"},
+		{"POST", "/api/v1/cart", "/api/v1/cart"},
+		{"GET", "/api/v1/cart", unmatchedRoute},
+		{"GET", "/nope", unmatchedRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			_, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil), -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
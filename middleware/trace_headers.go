@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderTraceID is the response header carrying the bare trace ID for support requests
+const HeaderTraceID = "X-Trace-Id"
+
+// TraceHeaders middleware echoes the server span as traceparent and X-Trace-Id response
+// headers. It must be registered after otelfiber so the server span is in the user context.
+func TraceHeaders() fiber.Handler {
+	propagator := propagation.TraceContext{}
+
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			carrier := propagation.MapCarrier{}
+			propagator.Inject(ctx, carrier)
+			for key, value := range carrier {
+				c.Set(key, value)
+			}
+			c.Set(HeaderTraceID, spanContext.TraceID().String())
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fiber-api/schemas"
+	"fiber-api/telemetry"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceHeaders(t *testing.T) {
+	traceID := trace.TraceID{0x0a, 0x0b}
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: ErrorHandler(telemetry.NewMockTelemetryProvider()),
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(trace.ContextWithSpanContext(context.Background(), spanContext))
+		return c.Next()
+	})
+	app.Use(TraceHeaders())
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/fail", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, traceID.String(), resp.Header.Get(HeaderTraceID))
+	assert.Equal(t, "00-"+traceID.String()+"-"+spanContext.SpanID().String()+"-01", resp.Header.Get("traceparent"))
+
+	var body schemas.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.True(t, body.Error)
+	assert.Equal(t, "boom", body.Message)
+	assert.Equal(t, traceID.String(), body.TraceID)
+}
+
+func TestTraceHeaders_NoSpan(t *testing.T) {
+	app := fiber.New()
+	app.Use(TraceHeaders())
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health", nil))
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.Header.Get(HeaderTraceID))
+	assert.Empty(t, resp.Header.Get("traceparent"))
+}
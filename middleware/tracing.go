@@ -1,79 +1,123 @@
 package middleware
 
 import (
+	"errors"
+	"fiber-api/config"
 	"fiber-api/telemetry"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 )
 
-// DetailedTracing middleware creates granular spans for HTTP request lifecycle
+// Phase span names, kept low-cardinality so they aggregate across routes
+const (
+	spanHandler           = "http.handler"
+	spanResponseSerialize = "http.response.serialize"
+)
+
+// Body capture event names and attribute prefixes
+const (
+	eventRequestBody  = "http.request.body"
+	eventResponseBody = "http.response.body"
+)
+
+// DetailedTracing middleware creates child spans of the server span for each request phase:
+// running the handler chain and serializing error responses. It must be registered after
+// otelfiber so the server span is already in the user context.
+//
+// Some phases have no span of their own. fasthttp reads the whole request body before any
+// middleware runs, so the server span only records its size. Successful responses are
+// serialized by the handlers themselves, e.g. with c.JSON, so that cost is part of the handler
+// span; only error responses rendered here get a serialization span. The final socket write
+// happens in fasthttp after the handler chain returns, so it is covered by the server span.
+//
+// When BODY_CAPTURE_ENABLED is set, sampled requests also record their request and response
+// bodies as span events, limited to allow-listed content types, with configured JSON fields and
+// fields matching the redaction rules redacted, and the content truncated to
+// BODY_CAPTURE_MAX_BYTES.
 func DetailedTracing(telemetryProvider telemetry.TelemetryProvider) fiber.Handler {
+	return detailedTracing(telemetryProvider, newBodyCapture(config.GetConfig(), telemetryProvider.GetRedactor()))
+}
+
+func detailedTracing(telemetryProvider telemetry.TelemetryProvider, capture *bodyCapture) fiber.Handler {
 	tracesExporter := telemetryProvider.GetTracesExporter()
+	routes := newRouteTemplates()
 
 	return func(c *fiber.Ctx) error {
-		start := time.Now()
 		ctx := c.UserContext()
+		captureBodies := capture.sampled()
 
-		// Create a span for request receive phase
-		receiveCtx, endReceive := tracesExporter.StartSpan(ctx, c.Method()+" "+c.Path()+" http receive")
-		tracesExporter.AddSpanEvent(receiveCtx, "request.received", []attribute.KeyValue{
-			attribute.String("http.method", c.Method()),
-			attribute.String("http.path", c.Path()),
-			attribute.String("http.user_agent", c.Get("User-Agent")),
-			attribute.String("http.remote_addr", c.IP()),
-			attribute.String("http.content_type", c.Get("Content-Type")),
-			attribute.Int64("http.request.size", int64(len(c.Body()))),
-		})
-		endReceive()
-
-		// Create a span for request processing phase
-		processCtx, endProcess := tracesExporter.StartSpan(ctx, c.Method()+" "+c.Path()+" http process")
-		tracesExporter.AddSpanEvent(processCtx, "processing.started", []attribute.KeyValue{
-			attribute.String("http.method", c.Method()),
-			attribute.String("http.path", c.Path()),
+		body := c.Request().Body()
+		tracesExporter.SetAttributes(ctx, []attribute.KeyValue{
+			// Fiber reuses request buffers, so values outliving the request are copied
+			attribute.String("http.request.header.content_type", utils.CopyString(c.Get(fiber.HeaderContentType))),
+			semconv.HTTPRequestBodySize(len(body)),
 		})
+		if captureBodies {
+			if attributes := capture.attributes(eventRequestBody, c.Get(fiber.HeaderContentType), c.Body()); attributes != nil {
+				tracesExporter.AddSpanEvent(ctx, eventRequestBody, attributes)
+			}
+		}
 
-		// Update the context for downstream handlers
-		c.SetUserContext(processCtx)
+		// Handler phase: downstream handlers and services create their spans under this one
+		handlerCtx, handlerSpan := tracesExporter.StartSpan(ctx, spanHandler)
+		c.SetUserContext(handlerCtx)
 
 		err := c.Next()
 
-		tracesExporter.AddSpanEvent(processCtx, "processing.completed", []attribute.KeyValue{
-			attribute.String("processing.status", func() string {
-				if err != nil {
-					return "error"
-				}
-				return "success"
-			}()),
-		})
-		endProcess()
+		status := c.Response().StatusCode()
+		description := ""
+		if err != nil {
+			// The error handler has not set the status yet, so derive it as fiber's default does
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+			description = err.Error()
+			handlerSpan.RecordError(err)
+		}
+		handlerSpan.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		// fiber's own route is this middleware's on a 404, so unmatched requests get no route
+		if route := routes.match(c); route != unmatchedRoute {
+			handlerSpan.SetAttributes(semconv.HTTPRoute(route))
+		}
+		// A 4xx is the client's error, not a failure of the handler
+		if status >= fiber.StatusInternalServerError {
+			handlerSpan.SetStatus(codes.Error, description)
+		}
+		handlerSpan.End()
 
-		// Create a span for response send phase
-		sendCtx, endSend := tracesExporter.StartSpan(ctx, c.Method()+" "+c.Path()+" http send")
+		// Restore the server span context for the remaining phases and outer middleware
+		c.SetUserContext(ctx)
 
-		duration := time.Since(start)
-		status := c.Response().StatusCode()
+		if err != nil {
+			// Serialization phase: render the error body here so its cost is measured, then
+			// report the request as handled so otelfiber does not invoke the error handler again
+			_, serializeSpan := tracesExporter.StartSpan(ctx, spanResponseSerialize)
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				serializeSpan.RecordError(handlerErr)
+				serializeSpan.SetStatus(codes.Error, handlerErr.Error())
+			}
+			serializeSpan.End()
 
-		// Add span events for response details
-		tracesExporter.AddSpanEvent(sendCtx, "response.sending", []attribute.KeyValue{
-			attribute.Int("http.status_code", status),
-			attribute.Int64("http.response.size", int64(len(c.Response().Body()))),
-			attribute.Float64("http.duration.ms", float64(duration.Nanoseconds())/1000000),
-			attribute.String("response.type", func() string {
-				if status >= 400 {
-					return "error"
-				} else if status >= 300 {
-					return "redirect"
-				}
-				return "success"
-			}()),
-		})
+			tracesExporter.RecordError(ctx, err, nil)
+		}
 
-		// End the send span
-		endSend()
+		responseBody := c.Response().Body()
+		tracesExporter.SetAttributes(ctx, []attribute.KeyValue{
+			semconv.HTTPResponseBodySize(len(responseBody)),
+		})
+		if captureBodies {
+			contentType := string(c.Response().Header.ContentType())
+			if attributes := capture.attributes(eventResponseBody, contentType, responseBody); attributes != nil {
+				tracesExporter.AddSpanEvent(ctx, eventResponseBody, attributes)
+			}
+		}
 
-		return err
+		return nil
 	}
-}
\ No newline at end of file
+}
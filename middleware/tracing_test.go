@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fiber-api/telemetry"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracingTelemetryProvider struct {
+	*telemetry.MockTelemetryProvider
+	tracesExporter telemetry.TracesExporter
+}
+
+func (p *tracingTelemetryProvider) GetTracesExporter() telemetry.TracesExporter {
+	return p.tracesExporter
+}
+
+// newTracingApp mimics otelfiber by starting a server span before DetailedTracing runs
+func newTracingApp(t *testing.T) (*fiber.App, *tracetest.SpanRecorder) {
+	return newCaptureTracingApp(t, &bodyCapture{})
+}
+
+func newCaptureTracingApp(t *testing.T, capture *bodyCapture) (*fiber.App, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+	tracer := tracerProvider.Tracer("test")
+
+	provider := &tracingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		tracesExporter:        telemetry.NewTracesExporter(tracer),
+	}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		ctx, span := tracer.Start(c.UserContext(), "server", trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		c.SetUserContext(ctx)
+		return c.Next()
+	})
+	app.Use(detailedTracing(provider, capture))
+
+	return app, recorder
+}
+
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	return spans
+}
+
+func TestDetailedTracing_SpanHierarchy(t *testing.T) {
+	app, recorder := newTracingApp(t)
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		// Handler spans must nest under the handler phase span
+		_, span := trace.SpanFromContext(c.UserContext()).TracerProvider().Tracer("test").Start(c.UserContext(), "service")
+		span.End()
+		return c.JSON(fiber.Map{"ok": true})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/items/42", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, "server")
+	require.Contains(t, spans, spanHandler)
+	require.Contains(t, spans, "service")
+	assert.NotContains(t, spans, spanResponseSerialize)
+
+	serverID := spans["server"].SpanContext().SpanID()
+	assert.Equal(t, serverID, spans[spanHandler].Parent().SpanID())
+	assert.Equal(t, spans[spanHandler].SpanContext().SpanID(), spans["service"].Parent().SpanID())
+
+	assert.Contains(t, spans[spanHandler].Attributes(), semconv.HTTPRoute("/items/:id"))
+	assert.Equal(t, codes.Unset, spans[spanHandler].Status().Code)
+}
+
+func TestDetailedTracing_HandlerError(t *testing.T) {
+	app, recorder := newTracingApp(t)
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/fail", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	spans := spansByName(recorder)
+	require.Contains(t, spans, spanResponseSerialize)
+	assert.Equal(t, spans["server"].SpanContext().SpanID(), spans[spanResponseSerialize].Parent().SpanID())
+
+	handlerSpan := spans[spanHandler]
+	assert.Equal(t, codes.Error, handlerSpan.Status().Code)
+	assert.Equal(t, "boom", handlerSpan.Status().Description)
+	require.NotEmpty(t, handlerSpan.Events())
+	assert.Equal(t, "exception", handlerSpan.Events()[0].Name)
+}
+
+func TestDetailedTracing_ClientErrorIsNotSpanError(t *testing.T) {
+	app, recorder := newTracingApp(t)
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusNotFound, "no such item")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/missing", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	handlerSpan := spansByName(recorder)[spanHandler]
+	require.NotNil(t, handlerSpan)
+	assert.Equal(t, codes.Unset, handlerSpan.Status().Code)
+	assert.Contains(t, handlerSpan.Attributes(), semconv.HTTPResponseStatusCode(fiber.StatusNotFound))
+	require.NotEmpty(t, handlerSpan.Events())
+	assert.Equal(t, "exception", handlerSpan.Events()[0].Name)
+}
+
+func TestDetailedTracing_UnmatchedRouteHasNoHTTPRoute(t *testing.T) {
+	app, recorder := newTracingApp(t)
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/unknown/path", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	handlerSpan := spansByName(recorder)[spanHandler]
+	require.NotNil(t, handlerSpan)
+	for _, attr := range handlerSpan.Attributes() {
+		assert.NotEqual(t, semconv.HTTPRouteKey, attr.Key, "a 404 must not be labelled with a route")
+	}
+}
@@ -0,0 +1,20 @@
+package schemas
+
+import "time"
+
+// LogLevelRequest changes the level, the level overrides, or both
+type LogLevelRequest struct {
+	Level string `json:"level"`
+	// Overrides replaces the override rules, e.g. "route:/api/v1/cart=debug"; "" clears them
+	Overrides *string `json:"overrides"`
+	// TTL is an optional Go duration such as "15m" after which the configuration is restored
+	TTL string `json:"ttl"`
+}
+
+type LogLevelResponse struct {
+	Level     string   `json:"level"`
+	Overrides []string `json:"overrides"`
+	// RevertAt and OverridesRevertAt are set while a change with a TTL is pending
+	RevertAt          *time.Time `json:"revertAt,omitempty"`
+	OverridesRevertAt *time.Time `json:"overridesRevertAt,omitempty"`
+}
@@ -5,7 +5,7 @@ import "time"
 type Item struct {
 	ID       string  `json:"id" validate:"required"`
 	Name     string  `json:"name" validate:"required,min=1,max=100"`
-	Price    float64 `json:"price" validate:"required,min=0"`
+	Price    float64 `json:"price" validate:"min=0"`
 	Quantity int     `json:"quantity" validate:"required,min=1"`
 }
 
@@ -33,4 +33,6 @@ type ErrorResponse struct {
 	Error     bool      `json:"error"`
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
-}
\ No newline at end of file
+	TraceID   string    `json:"traceId,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+}
@@ -8,10 +8,40 @@ const (
 	ErrorsTotal                = "fiber.shbm.errors.total"
 	CartCurrentItems           = "fiber.shbm.cart.current.items"
 	CartCurrentValue           = "fiber.shbm.cart.current.value"
+	CartOpenTotal              = "fiber.shbm.cart.open.total"
+	CartAverageItems           = "fiber.shbm.cart.average.items"
+	CartAbandonedTotal         = "fiber.shbm.cart.abandoned.total"
 	CartRequestsTotal          = "fiber.shbm.cart.requests.total"
 	CartOperationsTotal        = "fiber.shbm.cart.operations.total"
 	CartItemsTotal             = "fiber.shbm.cart.items.total"
 	CartItemsPerRequest        = "fiber.shbm.cart.items.per.request"
 	HealthChecksTotal          = "fiber.shbm.health.checks.total"
 	IntentionalErrorsTotal     = "fiber.shbm.intentional.errors.total"
+	HTTPClientRequestsTotal    = "fiber.shbm.http.client.requests.total"
+	HTTPClientDurationSeconds  = "fiber.shbm.http.client.request.duration.seconds"
+)
+
+// Span metric name constants, derived from ended spans
+const (
+	SpanCallsTotal      = "fiber.shbm.span.calls.total"
+	SpanErrorsTotal     = "fiber.shbm.span.errors.total"
+	SpanDurationSeconds = "fiber.shbm.span.duration.seconds"
+)
+
+// Log pipeline metric name constants
+const (
+	LogHandlerErrorsTotal  = "fiber.shbm.log.handler.errors.total"
+	LogHandlerDroppedTotal = "fiber.shbm.log.handler.dropped.total"
+)
+
+// Runtime and process metric name constants
+const (
+	RuntimeGoroutines          = "fiber.shbm.runtime.goroutines"
+	RuntimeGCPauseSeconds      = "fiber.shbm.runtime.gc.pause.seconds"
+	RuntimeGCCyclesTotal       = "fiber.shbm.runtime.gc.cycles.total"
+	RuntimeHeapInUseBytes      = "fiber.shbm.runtime.heap.inuse.bytes"
+	RuntimeHeapAllocBytesTotal = "fiber.shbm.runtime.heap.alloc.bytes.total"
+	RuntimeSchedLatencySeconds = "fiber.shbm.runtime.sched.latency.seconds"
+	ProcessOpenFileDescriptors = "fiber.shbm.process.open.fds"
+	ProcessCPUTimeSecondsTotal = "fiber.shbm.process.cpu.time.seconds.total"
 )
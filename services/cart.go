@@ -2,31 +2,102 @@ package services
 
 import (
 	"context"
+	"fiber-api/config"
 	"fiber-api/schemas"
 	"fiber-api/telemetry"
+	"fiber-api/utils"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// Service span names, one per cart processing step
+const (
+	spanProcessCart   = "cart.process"
+	spanValidate      = "cart.validate"
+	spanPricing       = "cart.pricing"
+	spanBuildResponse = "cart.build_response"
+	spanPersist       = "cart.persist"
+)
+
+// eventValidationFailed marks a rejected cart; a client error, so the span status stays unset
+const eventValidationFailed = "cart.validation_failed"
+
+// ValidationError reports cart request fields that failed validation
+type ValidationError struct {
+	Messages []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid cart request: " + strings.Join(e.Messages, ", ")
+}
+
 type CartService struct {
 	metricsExporter telemetry.MetricsExporter
+	tracesExporter  telemetry.TracesExporter
+	logger          *slog.Logger
+	store           *CartStore
 }
 
+// cartLoggerName names the service's loggers, so that LOG_LEVEL_OVERRIDES can target the
+// service layer, e.g. logger:services=debug
+const cartLoggerName = "services.cart"
+
+// NewCartService creates the service; its logger is used outside requests, which log through
+// the request-scoped logger instead
 func NewCartService(telemetryProvider telemetry.TelemetryProvider) *CartService {
-	return &CartService{
+	cfg := config.GetConfig()
+	s := &CartService{
 		metricsExporter: telemetryProvider.GetMetricsExporter(),
+		tracesExporter:  telemetryProvider.GetTracesExporter(),
+		logger:          telemetryProvider.GetLogger().With("logger", cartLoggerName),
+		store:           NewCartStore(cfg.CartAbandonedAfter, cfg.CartMaxOpen),
+	}
+	s.registerCartMetrics()
+	return s
+}
+
+// registerCartMetrics reports aggregate cart state on every collection instead of per-user
+// gauges; all four gauges are observed from one Stats snapshot, and abandoned carts, a running
+// total, through a counter
+func (s *CartService) registerCartMetrics() {
+	names := []string{
+		schemas.CartOpenTotal,
+		schemas.CartCurrentValue,
+		schemas.CartCurrentItems,
+		schemas.CartAverageItems,
+	}
+	err := s.metricsExporter.RegisterObservableGauges(names, func(ctx context.Context, observe func(string, float64, []attribute.KeyValue)) {
+		stats := s.store.Stats(time.Now())
+		observe(schemas.CartOpenTotal, float64(stats.OpenCarts), nil)
+		observe(schemas.CartCurrentValue, stats.TotalValue, nil)
+		observe(schemas.CartCurrentItems, float64(stats.TotalItems), nil)
+		observe(schemas.CartAverageItems, stats.AverageItems, nil)
+	})
+	if err != nil {
+		s.logger.Error("Failed to register cart gauges", "error", err)
+	}
+
+	err = s.metricsExporter.RegisterObservableCounter(schemas.CartAbandonedTotal, func(ctx context.Context) int64 {
+		return s.store.Abandoned(time.Now())
+	})
+	if err != nil {
+		s.logger.Error("Failed to register cart abandoned counter", "error", err)
 	}
 }
 
 func (s *CartService) ProcessCart(ctx context.Context, req schemas.CartRequest) (*schemas.CartResponse, error) {
 	itemCount := len(req.Items)
 
+	ctx, span := s.tracesExporter.StartSpan(ctx, spanProcessCart,
+		telemetry.WithAttributes(attribute.Int("cart.item_count", itemCount)))
+	defer span.End()
+
 	// Record cart processing metrics
 	attributes := []attribute.KeyValue{
-		attribute.String("user_id", req.UserID),
 		attribute.Int("item_count", itemCount),
 	}
 	s.metricsExporter.RecordCounter(ctx, schemas.CartOperationsTotal, 1, attributes)
@@ -35,26 +106,86 @@ func (s *CartService) ProcessCart(ctx context.Context, req schemas.CartRequest)
 	}
 
 	// Log cart processing with trace context
-	slog.InfoContext(ctx, "Processing cart request", "userId", req.UserID, "itemCount", itemCount)
+	// The request-scoped logger already carries the user ID
+	logger := s.log(ctx)
+	logger.InfoContext(ctx, "Processing cart request", "itemCount", itemCount)
+
+	if err := s.validate(ctx, req); err != nil {
+		span.SetAttributes(attribute.Bool("cart.valid", false))
+		return nil, err
+	}
 
-	total := s.calculateTotal(req.Items)
+	total := s.price(ctx, req.Items)
+	response := s.buildResponse(ctx, req, total)
+	s.persist(ctx, response)
 
+	span.SetAttributes(
+		attribute.String("cart.id", response.ID),
+		attribute.Float64("cart.total", total),
+	)
+
+	// Log successful processing
+	logger.InfoContext(ctx, "Cart processed successfully",
+		"cartId", response.ID,
+		"total", total,
+		"itemCount", itemCount)
+
+	return response, nil
+}
+
+// log returns the request-scoped logger of ctx, named after the service. Each call derives a new
+// logger whose level overrides are resolved afresh, so callers derive it once per operation.
+func (s *CartService) log(ctx context.Context) *slog.Logger {
+	return telemetry.FromContext(ctx).With("logger", cartLoggerName)
+}
+
+// validate checks the request against its struct validation rules
+func (s *CartService) validate(ctx context.Context, req schemas.CartRequest) error {
+	_, span := s.tracesExporter.StartSpan(ctx, spanValidate)
+	defer span.End()
+
+	messages := utils.ValidateStruct(req)
+	span.SetAttributes(attribute.Int("validation.error_count", len(messages)))
+	if len(messages) > 0 {
+		span.AddEvent(eventValidationFailed, attribute.StringSlice("validation.errors", messages))
+		return &ValidationError{Messages: messages}
+	}
+	return nil
+}
+
+func (s *CartService) price(ctx context.Context, items []schemas.Item) float64 {
+	_, span := s.tracesExporter.StartSpan(ctx, spanPricing,
+		telemetry.WithAttributes(attribute.Int("cart.item_count", len(items))))
+	defer span.End()
+
+	total := s.calculateTotal(items)
+	span.SetAttributes(attribute.Float64("cart.total", total))
+	return total
+}
+
+func (s *CartService) buildResponse(ctx context.Context, req schemas.CartRequest, total float64) *schemas.CartResponse {
+	_, span := s.tracesExporter.StartSpan(ctx, spanBuildResponse)
+	defer span.End()
+
+	now := time.Now()
 	response := &schemas.CartResponse{
 		ID:        uuid.New().String(),
 		UserID:    req.UserID,
 		Items:     req.Items,
 		Total:     total,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
+	span.SetAttributes(attribute.String("cart.id", response.ID))
+	return response
+}
 
-	// Log successful processing
-	slog.InfoContext(ctx, "Cart processed successfully",
-		"cartId", response.ID,
-		"total", total,
-		"itemCount", itemCount)
+func (s *CartService) persist(ctx context.Context, response *schemas.CartResponse) {
+	_, span := s.tracesExporter.StartSpan(ctx, spanPersist,
+		telemetry.WithAttributes(attribute.String("cart.id", response.ID)))
+	defer span.End()
 
-	return response, nil
+	s.store.Save(*response)
 }
 
 func (s *CartService) calculateTotal(items []schemas.Item) float64 {
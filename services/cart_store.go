@@ -0,0 +1,107 @@
+package services
+
+import (
+	"container/list"
+	"fiber-api/schemas"
+	"sync"
+	"time"
+)
+
+// CartStats is an aggregate snapshot of every open cart
+type CartStats struct {
+	OpenCarts    int
+	TotalValue   float64
+	TotalItems   int
+	AverageItems float64
+}
+
+// CartStore keeps the latest open cart per user in memory. A cart idle for longer than
+// abandonedAfter is evicted and counted as abandoned; once maxCarts carts are open, saving a
+// new one evicts the least recently updated.
+type CartStore struct {
+	abandonedAfter time.Duration
+	maxCarts       int
+
+	mu        sync.Mutex
+	carts     map[string]*list.Element
+	order     *list.List // of schemas.CartResponse, most recently saved first
+	abandoned int64
+}
+
+// NewCartStore creates a store; an abandonedAfter or maxCarts of 0 disables that limit
+func NewCartStore(abandonedAfter time.Duration, maxCarts int) *CartStore {
+	return &CartStore{
+		abandonedAfter: abandonedAfter,
+		maxCarts:       maxCarts,
+		carts:          make(map[string]*list.Element),
+		order:          list.New(),
+	}
+}
+
+// Save replaces the open cart of the cart's user
+func (s *CartStore) Save(cart schemas.CartResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictAbandoned(cart.UpdatedAt)
+	if element, ok := s.carts[cart.UserID]; ok {
+		element.Value = cart
+		s.order.MoveToFront(element)
+		return
+	}
+	if s.maxCarts > 0 && len(s.carts) >= s.maxCarts {
+		s.remove(s.order.Back())
+	}
+	s.carts[cart.UserID] = s.order.PushFront(cart)
+}
+
+// Stats evicts the carts abandoned by now and aggregates the remaining open carts
+func (s *CartStore) Stats(now time.Time) CartStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictAbandoned(now)
+	var stats CartStats
+	for element := s.order.Front(); element != nil; element = element.Next() {
+		cart := element.Value.(schemas.CartResponse)
+		stats.OpenCarts++
+		stats.TotalValue += cart.Total
+		stats.TotalItems += len(cart.Items)
+	}
+	if stats.OpenCarts > 0 {
+		stats.AverageItems = float64(stats.TotalItems) / float64(stats.OpenCarts)
+	}
+
+	return stats
+}
+
+// Abandoned evicts the carts abandoned by now and returns how many carts have been evicted for
+// being idle since the store was created
+func (s *CartStore) Abandoned(now time.Time) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictAbandoned(now)
+	return s.abandoned
+}
+
+// evictAbandoned must be called with mu held; carts are saved in time order, so the idle ones
+// are at the back of the list
+func (s *CartStore) evictAbandoned(now time.Time) {
+	if s.abandonedAfter <= 0 {
+		return
+	}
+	for element := s.order.Back(); element != nil; element = s.order.Back() {
+		if now.Sub(element.Value.(schemas.CartResponse).UpdatedAt) <= s.abandonedAfter {
+			return
+		}
+		s.remove(element)
+		s.abandoned++
+	}
+}
+
+// remove must be called with mu held
+func (s *CartStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.carts, element.Value.(schemas.CartResponse).UserID)
+}
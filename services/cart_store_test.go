@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fiber-api/schemas"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCartStore_Stats(t *testing.T) {
+	store := NewCartStore(30*time.Minute, 0)
+	now := time.Now()
+
+	store.Save(schemas.CartResponse{
+		UserID:    "user1",
+		Items:     []schemas.Item{{ID: "item1"}, {ID: "item2"}},
+		Total:     40,
+		UpdatedAt: now.Add(-time.Hour),
+	})
+	store.Save(schemas.CartResponse{
+		UserID:    "user2",
+		Items:     []schemas.Item{{ID: "item3"}},
+		Total:     10,
+		UpdatedAt: now,
+	})
+
+	stats := store.Stats(now)
+
+	// user1's cart was idle past the abandonment window, so it is evicted and counted
+	assert.Equal(t, 1, stats.OpenCarts)
+	assert.Equal(t, 10.0, stats.TotalValue)
+	assert.Equal(t, 1, stats.TotalItems)
+	assert.Equal(t, 1.0, stats.AverageItems)
+	assert.Equal(t, int64(1), store.Abandoned(now))
+
+	assert.Equal(t, int64(2), store.Abandoned(now.Add(time.Hour)))
+	assert.Equal(t, 0, store.Stats(now.Add(time.Hour)).OpenCarts)
+}
+
+func TestCartStore_SaveReplacesUserCart(t *testing.T) {
+	store := NewCartStore(30*time.Minute, 0)
+	now := time.Now()
+
+	store.Save(schemas.CartResponse{UserID: "user1", Total: 10, UpdatedAt: now.Add(-10 * time.Minute)})
+	store.Save(schemas.CartResponse{UserID: "user1", Total: 25, UpdatedAt: now})
+
+	stats := store.Stats(now.Add(25 * time.Minute))
+
+	assert.Equal(t, 1, stats.OpenCarts)
+	assert.Equal(t, 25.0, stats.TotalValue)
+	assert.Equal(t, int64(0), store.Abandoned(now.Add(25*time.Minute)))
+}
+
+func TestCartStore_MaxCartsEvictsLeastRecentlyUpdated(t *testing.T) {
+	store := NewCartStore(0, 2)
+	now := time.Now()
+
+	store.Save(schemas.CartResponse{UserID: "user1", Total: 1, UpdatedAt: now})
+	store.Save(schemas.CartResponse{UserID: "user2", Total: 2, UpdatedAt: now.Add(time.Second)})
+	store.Save(schemas.CartResponse{UserID: "user1", Total: 4, UpdatedAt: now.Add(2 * time.Second)})
+	store.Save(schemas.CartResponse{UserID: "user3", Total: 8, UpdatedAt: now.Add(3 * time.Second)})
+
+	stats := store.Stats(now.Add(time.Hour))
+
+	// user2 was updated least recently; carts evicted for space are not abandoned
+	assert.Equal(t, 2, stats.OpenCarts)
+	assert.Equal(t, 12.0, stats.TotalValue)
+	assert.Equal(t, int64(0), store.Abandoned(now.Add(time.Hour)))
+}
+
+func TestCartStore_StatsEmpty(t *testing.T) {
+	stats := NewCartStore(time.Minute, 10).Stats(time.Now())
+
+	assert.Equal(t, CartStats{}, stats)
+}
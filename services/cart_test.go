@@ -8,8 +8,32 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+type tracingTelemetryProvider struct {
+	*telemetry.MockTelemetryProvider
+	tracesExporter telemetry.TracesExporter
+}
+
+func (p *tracingTelemetryProvider) GetTracesExporter() telemetry.TracesExporter {
+	return p.tracesExporter
+}
+
+func newTracedCartService(t *testing.T) (*CartService, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+
+	return NewCartService(&tracingTelemetryProvider{
+		MockTelemetryProvider: telemetry.NewMockTelemetryProvider(),
+		tracesExporter:        telemetry.NewTracesExporter(tracerProvider.Tracer("test")),
+	}), recorder
+}
+
 func TestCartService_ProcessCart(t *testing.T) {
 	mockProvider := telemetry.NewMockTelemetryProvider()
 
@@ -119,3 +143,70 @@ func TestCartService_calculateTotal(t *testing.T) {
 		})
 	}
 }
+
+func TestCartService_ProcessCart_Spans(t *testing.T) {
+	service, recorder := newTracedCartService(t)
+
+	response, err := service.ProcessCart(context.Background(), schemas.CartRequest{
+		UserID: "user123",
+		Items:  []schemas.Item{{ID: "item1", Name: "Product A", Price: 10, Quantity: 3}},
+	})
+	require.NoError(t, err)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, spanProcessCart)
+	parent := spans[spanProcessCart].SpanContext().SpanID()
+
+	for _, name := range []string{spanValidate, spanPricing, spanBuildResponse, spanPersist} {
+		require.Contains(t, spans, name)
+		assert.Equal(t, parent, spans[name].Parent().SpanID(), name)
+	}
+
+	assert.Contains(t, spans[spanPricing].Attributes(), attribute.Float64("cart.total", 30))
+	assert.Contains(t, spans[spanPersist].Attributes(), attribute.String("cart.id", response.ID))
+	assert.Contains(t, spans[spanProcessCart].Attributes(), attribute.Int("cart.item_count", 1))
+}
+
+func TestCartService_ProcessCart_ValidationError(t *testing.T) {
+	service, recorder := newTracedCartService(t)
+
+	_, err := service.ProcessCart(context.Background(), schemas.CartRequest{
+		UserID: "user123",
+		Items:  []schemas.Item{{ID: "item1", Name: "Product A", Price: 10}},
+	})
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{"quantity is required"}, validationErr.Messages)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		assert.NotEqual(t, spanPricing, span.Name(), "pricing must not run for invalid carts")
+		spans[span.Name()] = span
+	}
+
+	// A rejected cart is the client's error, not a failure of the service
+	require.Contains(t, spans, spanValidate)
+	require.Contains(t, spans, spanProcessCart)
+	assert.Equal(t, codes.Unset, spans[spanValidate].Status().Code)
+	assert.Equal(t, codes.Unset, spans[spanProcessCart].Status().Code)
+	require.Len(t, spans[spanValidate].Events(), 1)
+	assert.Equal(t, eventValidationFailed, spans[spanValidate].Events()[0].Name)
+	assert.Contains(t, spans[spanValidate].Events()[0].Attributes,
+		attribute.StringSlice("validation.errors", []string{"quantity is required"}))
+	assert.Contains(t, spans[spanProcessCart].Attributes(), attribute.Bool("cart.valid", false))
+}
+
+func TestCartService_ProcessCart_FreeItem(t *testing.T) {
+	service := NewCartService(telemetry.NewMockTelemetryProvider())
+
+	response, err := service.ProcessCart(context.Background(), schemas.CartRequest{
+		UserID: "user123",
+		Items:  []schemas.Item{{ID: "gift", Name: "Free gift", Price: 0, Quantity: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, response.Total)
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import "log/slog"
+
+// attrGroups lets a handler add top-level attributes to records of loggers derived with
+// WithGroup. Such a handler keeps the groups, and the attributes added inside each, instead of
+// passing them to the wrapped handler, and nests the record's attributes itself in Handle.
+type attrGroups struct {
+	names []string
+	// attrs holds the attributes added inside each open group, one slice per group
+	attrs [][]slog.Attr
+}
+
+func (g attrGroups) empty() bool {
+	return len(g.names) == 0
+}
+
+// withGroup opens a group; an empty name is ignored, as slog does
+func (g attrGroups) withGroup(name string) attrGroups {
+	if name == "" {
+		return g
+	}
+	return attrGroups{
+		names: append(g.names[:len(g.names):len(g.names)], name),
+		attrs: append(g.attrs[:len(g.attrs):len(g.attrs)], nil),
+	}
+}
+
+// withAttrs adds attributes to the innermost group; it must only be called when not empty
+func (g attrGroups) withAttrs(attrs []slog.Attr) attrGroups {
+	last := len(g.attrs) - 1
+	grouped := g.attrs[:last:last]
+	grouped = append(grouped, append(g.attrs[last][:len(g.attrs[last]):len(g.attrs[last])], attrs...))
+	return attrGroups{names: g.names, attrs: grouped}
+}
+
+// apply returns a record whose attributes are nested in the groups, leaving room for the
+// caller to add top-level ones
+func (g attrGroups) apply(record slog.Record) slog.Record {
+	if g.empty() {
+		return record
+	}
+
+	var attrs []slog.Attr
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	// Build the groups from the innermost out; empty groups are dropped as slog does
+	for i := len(g.names) - 1; i >= 0; i-- {
+		contents := append(g.attrs[i][:len(g.attrs[i]):len(g.attrs[i])], attrs...)
+		attrs = nil
+		if len(contents) > 0 {
+			attrs = []slog.Attr{{Key: g.names[i], Value: slog.GroupValue(contents...)}}
+		}
+	}
+
+	grouped := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	grouped.AddAttrs(attrs...)
+	return grouped
+}
@@ -0,0 +1,176 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// baggageOverflowValue replaces metric attribute values once a key's value limit is reached
+const baggageOverflowValue = "_other"
+
+// BaggageFilter selects allow-listed baggage members so that arbitrary client-supplied
+// baggage never becomes span, log or metric attributes
+type BaggageFilter struct {
+	keys []string
+}
+
+// NewBaggageFilter creates a filter from a comma-separated list of baggage keys
+func NewBaggageFilter(keys string) *BaggageFilter {
+	filter := &BaggageFilter{}
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if key != "" {
+			filter.keys = append(filter.keys, key)
+		}
+	}
+	return filter
+}
+
+// Empty reports whether no baggage keys are allowed
+func (f *BaggageFilter) Empty() bool {
+	return f == nil || len(f.keys) == 0
+}
+
+// Attributes returns the allow-listed baggage members in ctx as attributes
+func (f *BaggageFilter) Attributes(ctx context.Context) []attribute.KeyValue {
+	if f.Empty() {
+		return nil
+	}
+
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return nil
+	}
+
+	var attributes []attribute.KeyValue
+	for _, key := range f.keys {
+		if member := bag.Member(key); member.Key() != "" {
+			attributes = append(attributes, attribute.String(key, member.Value()))
+		}
+	}
+	return attributes
+}
+
+// BaggageSpanProcessor copies allow-listed baggage onto every span when it starts
+type BaggageSpanProcessor struct {
+	filter *BaggageFilter
+}
+
+func NewBaggageSpanProcessor(filter *BaggageFilter) *BaggageSpanProcessor {
+	return &BaggageSpanProcessor{filter: filter}
+}
+
+func (p *BaggageSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if attributes := p.filter.Attributes(parent); len(attributes) > 0 {
+		s.SetAttributes(attributes...)
+	}
+}
+
+func (p *BaggageSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {}
+
+func (p *BaggageSpanProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (p *BaggageSpanProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// BaggageHandler adds allow-listed baggage from the record context to every log record
+type BaggageHandler struct {
+	handler slog.Handler
+	filter  *BaggageFilter
+}
+
+func NewBaggageHandler(handler slog.Handler, filter *BaggageFilter) *BaggageHandler {
+	return &BaggageHandler{
+		handler: handler,
+		filter:  filter,
+	}
+}
+
+func (b *BaggageHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return b.handler.Enabled(ctx, level)
+}
+
+func (b *BaggageHandler) Handle(ctx context.Context, record slog.Record) error {
+	for _, attr := range b.filter.Attributes(ctx) {
+		record.AddAttrs(slog.String(string(attr.Key), attr.Value.AsString()))
+	}
+	return b.handler.Handle(ctx, record)
+}
+
+func (b *BaggageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &BaggageHandler{
+		handler: b.handler.WithAttrs(attrs),
+		filter:  b.filter,
+	}
+}
+
+func (b *BaggageHandler) WithGroup(name string) slog.Handler {
+	return &BaggageHandler{
+		handler: b.handler.WithGroup(name),
+		filter:  b.filter,
+	}
+}
+
+// baggageMetricAttributes adds allow-listed baggage to metric attributes, capping the number
+// of distinct values per key so a misbehaving client cannot explode series cardinality
+type baggageMetricAttributes struct {
+	filter    *BaggageFilter
+	maxValues int
+
+	mu   sync.Mutex
+	seen map[string]map[string]struct{}
+}
+
+func newBaggageMetricAttributes(filter *BaggageFilter, maxValues int) *baggageMetricAttributes {
+	return &baggageMetricAttributes{
+		filter:    filter,
+		maxValues: maxValues,
+		seen:      make(map[string]map[string]struct{}),
+	}
+}
+
+// enrich returns attributes with the limited baggage attributes from ctx appended
+func (b *baggageMetricAttributes) enrich(ctx context.Context, attributes []attribute.KeyValue) []attribute.KeyValue {
+	if b == nil {
+		return attributes
+	}
+
+	fromBaggage := b.filter.Attributes(ctx)
+	if len(fromBaggage) == 0 {
+		return attributes
+	}
+
+	enriched := make([]attribute.KeyValue, 0, len(attributes)+len(fromBaggage))
+	enriched = append(enriched, attributes...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, attr := range fromBaggage {
+		key := string(attr.Key)
+		value := attr.Value.AsString()
+
+		values, ok := b.seen[key]
+		if !ok {
+			values = make(map[string]struct{})
+			b.seen[key] = values
+		}
+		if _, ok := values[value]; !ok {
+			if len(values) >= b.maxValues {
+				value = baggageOverflowValue
+			} else {
+				values[value] = struct{}{}
+			}
+		}
+		enriched = append(enriched, attribute.String(key, value))
+	}
+	return enriched
+}
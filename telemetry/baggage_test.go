@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func contextWithBaggage(t *testing.T, header string) context.Context {
+	bag, err := baggage.Parse(header)
+	require.NoError(t, err)
+	return baggage.ContextWithBaggage(context.Background(), bag)
+}
+
+func TestBaggageFilter_Attributes(t *testing.T) {
+	ctx := contextWithBaggage(t, "tenant.id=acme,experiment.id=blue,secret=token")
+	filter := NewBaggageFilter(" tenant.id, experiment.id ,missing")
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("tenant.id", "acme"),
+		attribute.String("experiment.id", "blue"),
+	}, filter.Attributes(ctx))
+
+	assert.True(t, NewBaggageFilter("").Empty())
+	assert.Nil(t, NewBaggageFilter("").Attributes(ctx))
+}
+
+func TestBaggageSpanProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(NewBaggageSpanProcessor(NewBaggageFilter("tenant.id"))),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	defer tracerProvider.Shutdown(context.Background())
+
+	ctx := contextWithBaggage(t, "tenant.id=acme,secret=token")
+	_, span := tracerProvider.Tracer("test").Start(ctx, "work")
+	span.End()
+
+	require.Len(t, recorder.Ended(), 1)
+	attributes := recorder.Ended()[0].Attributes()
+	assert.Contains(t, attributes, attribute.String("tenant.id", "acme"))
+	assert.NotContains(t, attributes, attribute.String("secret", "token"))
+}
+
+func TestBaggageHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewBaggageHandler(slog.NewTextHandler(&buf, nil), NewBaggageFilter("tenant.id")))
+
+	logger.InfoContext(contextWithBaggage(t, "tenant.id=acme,secret=token"), "cart processed")
+
+	assert.Contains(t, buf.String(), "tenant.id=acme")
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestBaggageMetricAttributes_LimitsCardinality(t *testing.T) {
+	limiter := newBaggageMetricAttributes(NewBaggageFilter("tenant.id"), 2)
+	base := []attribute.KeyValue{attribute.String("method", "GET")}
+
+	for _, tenant := range []string{"a", "b"} {
+		enriched := limiter.enrich(contextWithBaggage(t, "tenant.id="+tenant), base)
+		assert.Contains(t, enriched, attribute.String("tenant.id", tenant))
+	}
+
+	enriched := limiter.enrich(contextWithBaggage(t, "tenant.id=c"), base)
+	assert.Contains(t, enriched, attribute.String("tenant.id", baggageOverflowValue))
+
+	enriched = limiter.enrich(contextWithBaggage(t, "tenant.id=a"), base)
+	assert.Contains(t, enriched, attribute.String("tenant.id", "a"))
+	assert.Len(t, base, 1)
+
+	var disabled *baggageMetricAttributes
+	assert.Equal(t, base, disabled.enrich(contextWithBaggage(t, "tenant.id=a"), base))
+}
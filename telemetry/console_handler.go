@@ -0,0 +1,211 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Console log formats selected by LOG_FORMAT
+const (
+	LogFormatText   = "text"
+	LogFormatJSON   = "json"
+	LogFormatPretty = "pretty"
+)
+
+// ConsoleOptions configures the console log handler
+type ConsoleOptions struct {
+	// Format is text, json or pretty
+	Format string
+	// AddSource records the file and line of the logging call
+	AddSource bool
+	// Service is added as the service field of JSON records
+	Service string
+	// Level is the minimum level the handler accepts
+	Level slog.Leveler
+}
+
+// NewConsoleHandler creates the console handler for a format. Every format carries trace_id and
+// span_id; JSON records use the stable field names ts, level, msg, trace_id, span_id and service.
+func NewConsoleHandler(w io.Writer, options ConsoleOptions) (slog.Handler, error) {
+	handlerOptions := &slog.HandlerOptions{
+		AddSource: options.AddSource,
+		Level:     options.Level,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(options.Format)) {
+	case "", LogFormatText:
+		handler = slog.NewTextHandler(w, handlerOptions)
+	case LogFormatJSON:
+		handlerOptions.ReplaceAttr = jsonReplaceAttr
+		handler = slog.NewJSONHandler(w, handlerOptions).WithAttrs([]slog.Attr{slog.String("service", options.Service)})
+	case LogFormatPretty:
+		handler = NewPrettyHandler(w, handlerOptions, os.Getenv("NO_COLOR") == "")
+	default:
+		return nil, fmt.Errorf("unknown log format %q", options.Format)
+	}
+	return NewTraceContextHandler(handler), nil
+}
+
+// jsonReplaceAttr renames the built-in fields to the names log pipelines index on
+func jsonReplaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return attr
+	}
+	switch attr.Key {
+	case slog.TimeKey:
+		attr.Key = "ts"
+		attr.Value = slog.StringValue(attr.Value.Time().UTC().Format(time.RFC3339Nano))
+	case slog.LevelKey:
+		attr.Value = slog.StringValue(strings.ToLower(attr.Value.String()))
+	}
+	return attr
+}
+
+// ANSI colours used by the pretty format
+const (
+	colorReset  = "\033[0m"
+	colorDim    = "\033[2m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+)
+
+// PrettyHandler writes compact, optionally colourised lines for local development:
+// time, level, message, then key=value attributes
+type PrettyHandler struct {
+	options slog.HandlerOptions
+	color   bool
+
+	mu *sync.Mutex
+	w  io.Writer
+
+	// prefix is the group path applied to attributes; attrs are the pre-rendered WithAttrs pairs
+	prefix string
+	attrs  []byte
+}
+
+func NewPrettyHandler(w io.Writer, options *slog.HandlerOptions, color bool) *PrettyHandler {
+	handler := &PrettyHandler{
+		color: color,
+		mu:    &sync.Mutex{},
+		w:     w,
+	}
+	if options != nil {
+		handler.options = *options
+	}
+	return handler
+}
+
+func (h *PrettyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.options.Level != nil {
+		minLevel = h.options.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *PrettyHandler) Handle(ctx context.Context, record slog.Record) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(h.paint(colorDim, record.Time.Format("15:04:05.000")))
+	buf.WriteByte(' ')
+	buf.WriteString(h.levelLabel(record.Level))
+	buf.WriteByte(' ')
+	buf.WriteString(record.Message)
+
+	if h.options.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		buf.WriteByte(' ')
+		buf.WriteString(h.paint(colorDim, filepath.Base(frame.File)+":"+strconv.Itoa(frame.Line)))
+	}
+
+	buf.Write(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		h.appendAttr(&buf, h.prefix, attr)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var buf bytes.Buffer
+	buf.Write(h.attrs)
+	for _, attr := range attrs {
+		h.appendAttr(&buf, h.prefix, attr)
+	}
+
+	derived := *h
+	derived.attrs = buf.Bytes()
+	return &derived
+}
+
+func (h *PrettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.prefix = h.prefix + name + "."
+	return &derived
+}
+
+func (h *PrettyHandler) appendAttr(buf *bytes.Buffer, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, child := range attr.Value.Group() {
+			h.appendAttr(buf, prefix, child)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(h.paint(colorCyan, prefix+attr.Key+"="))
+	buf.WriteString(value)
+}
+
+func (h *PrettyHandler) levelLabel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return h.paint(colorRed, "ERR")
+	case level >= slog.LevelWarn:
+		return h.paint(colorYellow, "WRN")
+	case level >= slog.LevelInfo:
+		return h.paint(colorGreen, "INF")
+	default:
+		return h.paint(colorBlue, "DBG")
+	}
+}
+
+func (h *PrettyHandler) paint(color, text string) string {
+	if !h.color {
+		return text
+	}
+	return color + text + colorReset
+}
@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testSpanContext() context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x0a},
+		SpanID:  trace.SpanID{0x0b},
+	}))
+}
+
+func TestNewConsoleHandler_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewConsoleHandler(&buf, ConsoleOptions{Format: "json", AddSource: true, Service: "fiber-api"})
+	require.NoError(t, err)
+
+	slog.New(handler).WarnContext(testSpanContext(), "cart processed", "itemCount", 2)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	for _, key := range []string{"ts", "level", "msg", "trace_id", "span_id", "service", "source"} {
+		assert.Contains(t, entry, key)
+	}
+	assert.NotContains(t, entry, "time")
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "cart processed", entry["msg"])
+	assert.Equal(t, "fiber-api", entry["service"])
+	assert.Equal(t, trace.TraceID{0x0a}.String(), entry["trace_id"])
+	assert.Equal(t, float64(2), entry["itemCount"])
+}
+
+func TestNewConsoleHandler_Text(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewConsoleHandler(&buf, ConsoleOptions{Format: "TEXT"})
+	require.NoError(t, err)
+
+	slog.New(handler).InfoContext(testSpanContext(), "hello")
+	assert.Contains(t, buf.String(), "msg=hello")
+	assert.Contains(t, buf.String(), "trace_id=")
+	assert.NotContains(t, buf.String(), "source=")
+
+	_, err = NewConsoleHandler(&buf, ConsoleOptions{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestPrettyHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewPrettyHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug}, false))
+
+	logger.With("logger", "services").WithGroup("cart").Debug("priced", "total", 30.5, "note", "two words")
+
+	line := buf.String()
+	assert.True(t, strings.HasSuffix(line, "\n"))
+	assert.Contains(t, line, " DBG priced ")
+	assert.Contains(t, line, "console_handler_test.go:")
+	assert.Contains(t, line, "logger=services")
+	assert.Contains(t, line, "cart.total=30.5")
+	assert.Contains(t, line, `cart.note="two words"`)
+	assert.NotContains(t, line, "\033[")
+
+	buf.Reset()
+	slog.New(NewPrettyHandler(&buf, nil, true)).Error("failed")
+	assert.Contains(t, buf.String(), colorRed+"ERR"+colorReset)
+}
@@ -5,19 +5,45 @@ import (
 	"log/slog"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ObservableGaugesCallback reports the values of a set of gauges through observe each time
+// metrics are collected
+type ObservableGaugesCallback func(ctx context.Context, observe func(name string, value float64, attributes []attribute.KeyValue))
+
+// ObservableCounterCallback returns the cumulative value of a counter each time metrics are
+// collected
+type ObservableCounterCallback func(ctx context.Context) int64
+
 type MetricsExporter interface {
 	RecordMetric(ctx context.Context, metricName string, value interface{}, attributes []attribute.KeyValue)
 	RecordCounter(ctx context.Context, name string, value int64, attributes []attribute.KeyValue)
 	RecordHistogram(ctx context.Context, name string, value float64, attributes []attribute.KeyValue)
 	RecordGauge(ctx context.Context, name string, value float64, attributes []attribute.KeyValue)
+	RecordUpDownCounter(ctx context.Context, name string, value int64, attributes []attribute.KeyValue)
+	RegisterObservableGauges(names []string, callback ObservableGaugesCallback) error
+	RegisterObservableCounter(name string, callback ObservableCounterCallback) error
+}
+
+// Span is a handle to a span started through TracesExporter
+type Span interface {
+	End()
+	SetAttributes(attributes ...attribute.KeyValue)
+	AddEvent(eventName string, attributes ...attribute.KeyValue)
+	RecordError(err error, attributes ...attribute.KeyValue)
+	SetStatus(code codes.Code, description string)
+	SpanContext() trace.SpanContext
 }
 
 type TracesExporter interface {
-	StartSpan(ctx context.Context, spanName string) (context.Context, func())
+	StartSpan(ctx context.Context, spanName string, opts ...SpanOption) (context.Context, Span)
 	AddSpanEvent(ctx context.Context, eventName string, attributes []attribute.KeyValue)
+	SetAttributes(ctx context.Context, attributes []attribute.KeyValue)
+	RecordError(ctx context.Context, err error, attributes []attribute.KeyValue)
+	SetStatus(ctx context.Context, code codes.Code, description string)
 }
 
 type TelemetryProvider interface {
@@ -25,5 +51,8 @@ type TelemetryProvider interface {
 	GetLogger() *slog.Logger
 	GetTracesExporter() TracesExporter
 	GetTracerProvider() *sdktrace.TracerProvider
+	GetLogLevel() *LogLevelController
+	GetRedactor() *Redactor
+	ReopenLogs() error
 	Shutdown(ctx context.Context) error
 }
@@ -3,42 +3,160 @@ package telemetry
 import (
 	"context"
 	"log/slog"
+	"strings"
+	"sync/atomic"
 )
 
-// LevelFilterHandler wraps another handler and filters by log level
+// LevelFilterHandler wraps another handler and filters by log level. The minimum level is read
+// on every call, so passing a *slog.LevelVar lets it change at runtime.
+//
+// Optional level overrides raise or lower the minimum for matching records. When several rules
+// apply, attribute rules win over route rules, which win over logger rules.
 type LevelFilterHandler struct {
-	handler  slog.Handler
-	minLevel slog.Level
+	handler   slog.Handler
+	minLevel  slog.Leveler
+	overrides *LevelOverrides
+
+	// groups and attrs describe the logger this handler was derived as through WithGroup and
+	// WithAttrs; they are only kept when overrides are in use
+	groups []string
+	attrs  []slog.Attr
+	names  []string
+
+	// static caches the logger and attribute rule results for the current rule set, since
+	// neither depends on the record
+	static *atomic.Pointer[staticLevels]
+}
+
+// staticLevels holds the rule results that depend only on the logger, for one rule set
+type staticLevels struct {
+	set         *levelRuleSet
+	attrLevel   slog.Level
+	attrMatch   bool
+	loggerLevel slog.Level
+	loggerMatch bool
 }
 
-func NewLevelFilterHandler(handler slog.Handler, minLevel slog.Level) *LevelFilterHandler {
+func NewLevelFilterHandler(handler slog.Handler, minLevel slog.Leveler) *LevelFilterHandler {
+	return NewLevelFilterHandlerWithOverrides(handler, minLevel, nil)
+}
+
+// NewLevelFilterHandlerWithOverrides creates a level filter that also applies override rules
+func NewLevelFilterHandlerWithOverrides(handler slog.Handler, minLevel slog.Leveler, overrides *LevelOverrides) *LevelFilterHandler {
 	return &LevelFilterHandler{
-		handler:  handler,
-		minLevel: minLevel,
+		handler:   handler,
+		minLevel:  minLevel,
+		overrides: overrides,
+		static:    &atomic.Pointer[staticLevels]{},
 	}
 }
 
 func (l *LevelFilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= l.minLevel && l.handler.Enabled(ctx, level)
+	set := l.overrides.load()
+	if set.empty() {
+		return level >= l.minLevel.Level() && l.handler.Enabled(ctx, level)
+	}
+
+	static := l.staticLevels(set)
+	if static.attrMatch {
+		return level >= static.attrLevel && l.handler.Enabled(ctx, level)
+	}
+	// The record's own attributes may still match an attribute rule; Handle decides then
+	if len(set.attr) > 0 && level >= set.minAttr {
+		return l.handler.Enabled(ctx, level)
+	}
+	return level >= l.threshold(ctx, set, static) && l.handler.Enabled(ctx, level)
 }
 
 func (l *LevelFilterHandler) Handle(ctx context.Context, record slog.Record) error {
-	if record.Level >= l.minLevel {
+	set := l.overrides.load()
+	if set.empty() {
+		if record.Level >= l.minLevel.Level() {
+			return l.handler.Handle(ctx, record)
+		}
+		return nil
+	}
+
+	static := l.staticLevels(set)
+	threshold, matched := static.attrLevel, static.attrMatch
+	if !matched && len(set.attr) > 0 {
+		record.Attrs(func(attr slog.Attr) bool {
+			threshold, matched = set.attrLevel(l.groups, []slog.Attr{attr})
+			return !matched
+		})
+	}
+	if !matched {
+		threshold = l.threshold(ctx, set, static)
+	}
+
+	if record.Level >= threshold {
 		return l.handler.Handle(ctx, record)
 	}
 	return nil
 }
 
+// threshold returns the minimum level from route and logger rules, falling back to the global level
+func (l *LevelFilterHandler) threshold(ctx context.Context, set *levelRuleSet, static *staticLevels) slog.Level {
+	if level, ok := set.routeLevel(ctx); ok {
+		return level
+	}
+	if static.loggerMatch {
+		return static.loggerLevel
+	}
+	return l.minLevel.Level()
+}
+
+func (l *LevelFilterHandler) staticLevels(set *levelRuleSet) *staticLevels {
+	if cached := l.static.Load(); cached != nil && cached.set == set {
+		return cached
+	}
+
+	static := &staticLevels{set: set}
+	static.attrLevel, static.attrMatch = set.attrLevel(nil, l.attrs)
+	static.loggerLevel, static.loggerMatch = set.loggerLevel(l.names)
+	l.static.Store(static)
+	return static
+}
+
 func (l *LevelFilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &LevelFilterHandler{
-		handler:  l.handler.WithAttrs(attrs),
-		minLevel: l.minLevel,
+	derived := l.derive(l.handler.WithAttrs(attrs))
+	if l.overrides == nil {
+		return derived
 	}
+
+	for _, attr := range attrs {
+		if attr.Key == loggerNameKey {
+			derived.names = append(derived.names, attr.Value.Resolve().String())
+		}
+		// Nest the attribute in its group path so attribute rules can match the qualified key
+		for i := len(l.groups) - 1; i >= 0; i-- {
+			attr = slog.Attr{Key: l.groups[i], Value: slog.GroupValue(attr)}
+		}
+		derived.attrs = append(derived.attrs, attr)
+	}
+	return derived
 }
 
 func (l *LevelFilterHandler) WithGroup(name string) slog.Handler {
+	derived := l.derive(l.handler.WithGroup(name))
+	if l.overrides == nil {
+		return derived
+	}
+
+	derived.groups = append(derived.groups, name)
+	derived.names = append(derived.names, strings.Join(derived.groups, "."))
+	return derived
+}
+
+// derive copies the handler for a child logger with fresh slices and cache
+func (l *LevelFilterHandler) derive(handler slog.Handler) *LevelFilterHandler {
 	return &LevelFilterHandler{
-		handler:  l.handler.WithGroup(name),
-		minLevel: l.minLevel,
+		handler:   handler,
+		minLevel:  l.minLevel,
+		overrides: l.overrides,
+		groups:    l.groups[:len(l.groups):len(l.groups)],
+		attrs:     l.attrs[:len(l.attrs):len(l.attrs)],
+		names:     l.names[:len(l.names):len(l.names)],
+		static:    &atomic.Pointer[staticLevels]{},
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevelRules(t *testing.T) {
+	rules, err := ParseLevelRules("logger:services=debug, route:/api/v1/cart/:id=DEBUG,attr:tenant.id=acme=warn")
+	require.NoError(t, err)
+	assert.Equal(t, []LevelRule{
+		{Kind: LevelRuleLogger, Key: "services", Level: slog.LevelDebug},
+		{Kind: LevelRuleRoute, Key: "/api/v1/cart/:id", Level: slog.LevelDebug},
+		{Kind: LevelRuleAttr, Key: "tenant.id", Value: "acme", Level: slog.LevelWarn},
+	}, rules)
+	assert.Equal(t, "attr:tenant.id=acme=warn", rules[2].String())
+
+	for _, invalid := range []string{"services=debug", "path:/cart=debug", "logger:services", "logger:=debug", "attr:tenant=debug", "route:/cart=loud"} {
+		_, err := ParseLevelRules(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	assert.True(t, matchRoute("/api/v1/cart", "/api/v1/cart"))
+	assert.True(t, matchRoute("/api/v1/cart", "/api/v1/cart/"))
+	assert.True(t, matchRoute("/items/:id", "/items/42"))
+	assert.True(t, matchRoute("/api/*", "/api/v1/cart"))
+	assert.False(t, matchRoute("/items/:id", "/items/42/reviews"))
+	assert.False(t, matchRoute("/api/v1/cart", "/api/v1/health"))
+}
+
+func newOverrideLogger(t *testing.T, spec string) (*slog.Logger, *bytes.Buffer, *LevelOverrides) {
+	rules, err := ParseLevelRules(spec)
+	require.NoError(t, err)
+	overrides := NewLevelOverrides(rules)
+
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(NewLevelFilterHandlerWithOverrides(handler, slog.LevelInfo, overrides)), &buf, overrides
+}
+
+func TestLevelFilterHandler_LoggerOverride(t *testing.T) {
+	logger, buf, _ := newOverrideLogger(t, "logger:services=debug,logger:noisy=error")
+
+	logger.With("logger", "services.cart").Debug("service debug")
+	logger.WithGroup("services").Debug("group debug")
+	logger.Debug("root debug")
+	logger.With("logger", "noisy").Warn("noisy warn")
+	logger.With("logger", "servicesx").Debug("prefix only")
+
+	assert.Contains(t, buf.String(), "service debug")
+	assert.Contains(t, buf.String(), "group debug")
+	assert.NotContains(t, buf.String(), "root debug")
+	assert.NotContains(t, buf.String(), "noisy warn")
+	assert.NotContains(t, buf.String(), "prefix only")
+}
+
+func TestLevelFilterHandler_RouteOverride(t *testing.T) {
+	logger, buf, _ := newOverrideLogger(t, "route:/api/v1/cart=debug")
+
+	logger.DebugContext(ContextWithRoute(context.Background(), "/api/v1/cart"), "cart debug")
+	logger.DebugContext(ContextWithRoute(context.Background(), "/api/v1/health"), "health debug")
+	logger.DebugContext(context.Background(), "no route debug")
+
+	assert.Contains(t, buf.String(), "cart debug")
+	assert.NotContains(t, buf.String(), "health debug")
+	assert.NotContains(t, buf.String(), "no route debug")
+}
+
+func TestLevelFilterHandler_AttrOverride(t *testing.T) {
+	logger, buf, _ := newOverrideLogger(t, "attr:tenant.id=acme=debug,route:/api/v1/cart=error")
+	ctx := ContextWithRoute(context.Background(), "/api/v1/cart")
+
+	logger.DebugContext(ctx, "record attr", "tenant.id", "acme")
+	logger.With("tenant.id", "acme").DebugContext(ctx, "logger attr")
+	logger.WithGroup("tenant").DebugContext(ctx, "grouped attr", "id", "acme")
+	logger.DebugContext(ctx, "other tenant", "tenant.id", "other")
+	logger.WarnContext(ctx, "route raised")
+
+	assert.Contains(t, buf.String(), "record attr")
+	assert.Contains(t, buf.String(), "logger attr")
+	assert.Contains(t, buf.String(), "grouped attr")
+	assert.NotContains(t, buf.String(), "other tenant")
+	assert.NotContains(t, buf.String(), "route raised", "route rule outranks the global level")
+}
+
+func TestLevelFilterHandler_OverridesChangeAtRuntime(t *testing.T) {
+	logger, buf, overrides := newOverrideLogger(t, "")
+	serviceLogger := logger.With("logger", "services")
+
+	serviceLogger.Debug("before")
+	overrides.Set([]LevelRule{{Kind: LevelRuleLogger, Key: "services", Level: slog.LevelDebug}})
+	serviceLogger.Debug("during")
+	overrides.Set(nil)
+	serviceLogger.Debug("after")
+
+	assert.NotContains(t, buf.String(), "before")
+	assert.Contains(t, buf.String(), "during")
+	assert.NotContains(t, buf.String(), "after")
+}
@@ -0,0 +1,254 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// LevelRuleKind selects what a level override rule matches
+type LevelRuleKind int
+
+const (
+	// LevelRuleLogger matches a logger name: its group path or "logger" attribute, including children
+	LevelRuleLogger LevelRuleKind = iota
+	// LevelRuleRoute matches the request path against a route template such as "/api/v1/cart/:id"
+	LevelRuleRoute
+	// LevelRuleAttr matches an attribute key and value on the logger or the record
+	LevelRuleAttr
+)
+
+var levelRuleKinds = map[string]LevelRuleKind{
+	"logger": LevelRuleLogger,
+	"route":  LevelRuleRoute,
+	"attr":   LevelRuleAttr,
+}
+
+// loggerNameKey is the attribute naming a logger, e.g. slog.With("logger", "services")
+const loggerNameKey = "logger"
+
+// LevelRule overrides the minimum log level for the records it matches
+type LevelRule struct {
+	Kind  LevelRuleKind
+	Key   string
+	Value string
+	Level slog.Level
+}
+
+// String formats the rule the way ParseLevelRules reads it
+func (r LevelRule) String() string {
+	switch r.Kind {
+	case LevelRuleRoute:
+		return "route:" + r.Key + "=" + LevelName(r.Level)
+	case LevelRuleAttr:
+		return "attr:" + r.Key + "=" + r.Value + "=" + LevelName(r.Level)
+	default:
+		return "logger:" + r.Key + "=" + LevelName(r.Level)
+	}
+}
+
+// ParseLevelRules parses comma-separated selector=level rules, where selector is logger:NAME,
+// route:TEMPLATE or attr:KEY=VALUE, e.g. "logger:services=debug,route:/api/v1/cart=debug"
+func ParseLevelRules(spec string) ([]LevelRule, error) {
+	var rules []LevelRule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		// The level follows the last "=", since attribute selectors contain one themselves
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid log level rule %q", entry)
+		}
+		kindName, selector, ok := strings.Cut(entry[:separator], ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid log level rule %q", entry)
+		}
+		kind, ok := levelRuleKinds[strings.ToLower(strings.TrimSpace(kindName))]
+		if !ok {
+			return nil, fmt.Errorf("unknown selector in log level rule %q", entry)
+		}
+		level, err := ParseLevel(entry[separator+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level rule %q: %w", entry, err)
+		}
+
+		rule := LevelRule{Kind: kind, Level: level}
+		if kind == LevelRuleAttr {
+			key, value, ok := strings.Cut(selector, "=")
+			if !ok {
+				return nil, fmt.Errorf("attribute log level rule %q needs key=value", entry)
+			}
+			rule.Key, rule.Value = strings.TrimSpace(key), strings.TrimSpace(value)
+		} else {
+			rule.Key = strings.TrimSpace(selector)
+		}
+		if rule.Key == "" {
+			return nil, fmt.Errorf("invalid log level rule %q", entry)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// levelRuleSet is an immutable, pre-split set of rules; a new set replaces it on every change
+type levelRuleSet struct {
+	rules  []LevelRule
+	logger []LevelRule
+	route  []LevelRule
+	attr   []LevelRule
+	// minAttr is the lowest level any attribute rule enables, used to answer Enabled before
+	// the record's own attributes are known
+	minAttr slog.Level
+}
+
+func newLevelRuleSet(rules []LevelRule) *levelRuleSet {
+	set := &levelRuleSet{rules: rules}
+	for _, rule := range rules {
+		switch rule.Kind {
+		case LevelRuleLogger:
+			set.logger = append(set.logger, rule)
+		case LevelRuleRoute:
+			set.route = append(set.route, rule)
+		case LevelRuleAttr:
+			if len(set.attr) == 0 || rule.Level < set.minAttr {
+				set.minAttr = rule.Level
+			}
+			set.attr = append(set.attr, rule)
+		}
+	}
+	return set
+}
+
+func (s *levelRuleSet) empty() bool {
+	return s == nil || len(s.rules) == 0
+}
+
+// loggerLevel returns the level of the first rule matching one of the logger names
+func (s *levelRuleSet) loggerLevel(names []string) (slog.Level, bool) {
+	for _, rule := range s.logger {
+		for _, name := range names {
+			if name == rule.Key || strings.HasPrefix(name, rule.Key+".") {
+				return rule.Level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (s *levelRuleSet) routeLevel(ctx context.Context) (slog.Level, bool) {
+	if len(s.route) == 0 {
+		return 0, false
+	}
+	route := RouteFromContext(ctx)
+	if route == "" {
+		return 0, false
+	}
+	for _, rule := range s.route {
+		if matchRoute(rule.Key, route) {
+			return rule.Level, true
+		}
+	}
+	return 0, false
+}
+
+// attrLevel returns the level of the first rule matching one of the attributes
+func (s *levelRuleSet) attrLevel(groups []string, attrs []slog.Attr) (slog.Level, bool) {
+	for _, rule := range s.attr {
+		for _, attr := range attrs {
+			if attrMatches(rule, groups, attr) {
+				return rule.Level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func attrMatches(rule LevelRule, groups []string, attr slog.Attr) bool {
+	if attr.Value.Kind() == slog.KindGroup {
+		nested := append(groups[:len(groups):len(groups)], attr.Key)
+		for _, child := range attr.Value.Group() {
+			if attrMatches(rule, nested, child) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if attr.Key != rule.Key && strings.Join(append(groups[:len(groups):len(groups)], attr.Key), ".") != rule.Key {
+		return false
+	}
+	return attr.Value.Resolve().String() == rule.Value
+}
+
+// matchRoute matches a path against a template whose ":name" segments match any one segment
+// and whose trailing "*" matches the rest of the path
+func matchRoute(template, path string) bool {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range templateSegments {
+		if segment == "*" {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(templateSegments) == len(pathSegments)
+}
+
+// LevelOverrides holds the level override rules shared by every LevelFilterHandler derived from
+// one logger. Rules are swapped atomically, so reading them on the hot path takes no lock.
+type LevelOverrides struct {
+	current atomic.Pointer[levelRuleSet]
+}
+
+func NewLevelOverrides(rules []LevelRule) *LevelOverrides {
+	overrides := &LevelOverrides{}
+	overrides.Set(rules)
+	return overrides
+}
+
+// Set replaces the rules
+func (o *LevelOverrides) Set(rules []LevelRule) {
+	o.current.Store(newLevelRuleSet(rules))
+}
+
+// Rules returns the current rules
+func (o *LevelOverrides) Rules() []LevelRule {
+	if set := o.load(); set != nil {
+		return set.rules
+	}
+	return nil
+}
+
+func (o *LevelOverrides) load() *levelRuleSet {
+	if o == nil {
+		return nil
+	}
+	return o.current.Load()
+}
+
+type routeContextKey struct{}
+
+// ContextWithRoute stores the request route so route level overrides apply to logs made with ctx
+func ContextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeContextKey{}, route)
+}
+
+// RouteFromContext returns the route stored by ContextWithRoute, or "" if there is none
+func RouteFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	route, _ := ctx.Value(routeContextKey{}).(string)
+	return route
+}
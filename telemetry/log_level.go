@@ -0,0 +1,150 @@
+package telemetry
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseLevel parses a log level name, rejecting names parseLogLevel would silently map to info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug", "info", "warn", "warning", "error":
+		return parseLogLevel(name), nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// LevelName returns the lower-case name used by LOG_LEVEL for a level
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// LogLevelController owns the level and level overrides shared by every log handler, so they
+// can be changed at runtime. A change may carry a TTL, after which what it changed reverts to
+// the configured value; a change without a TTL cancels the pending revert of what it changed.
+// The level and the overrides revert independently.
+type LogLevelController struct {
+	level     *slog.LevelVar
+	overrides *LevelOverrides
+
+	mu              sync.Mutex
+	configured      slog.Level
+	configuredRules []LevelRule
+	levelRevert     pendingRevert
+	overridesRevert pendingRevert
+}
+
+// pendingRevert is a scheduled return to the configured level or overrides
+type pendingRevert struct {
+	at    time.Time
+	timer *time.Timer
+}
+
+func (p *pendingRevert) stop() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.at = time.Time{}
+}
+
+func NewLogLevelController(configured slog.Level, rules []LevelRule) *LogLevelController {
+	level := &slog.LevelVar{}
+	level.Set(configured)
+	return &LogLevelController{
+		level:           level,
+		overrides:       NewLevelOverrides(rules),
+		configured:      configured,
+		configuredRules: rules,
+	}
+}
+
+// Leveler returns the shared level for use in handler options
+func (c *LogLevelController) Leveler() slog.Leveler {
+	return c.level
+}
+
+// Overrides returns the shared level overrides for use in a LevelFilterHandler
+func (c *LogLevelController) Overrides() *LevelOverrides {
+	return c.overrides
+}
+
+// Level returns the current level and, when a temporary change is active, when it reverts
+func (c *LogLevelController) Level() (slog.Level, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level.Level(), c.levelRevert.at
+}
+
+// OverrideRules returns the current override rules and, when a temporary change is active,
+// when they revert
+func (c *LogLevelController) OverrideRules() ([]LevelRule, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.overrides.Rules(), c.overridesRevert.at
+}
+
+// Set changes the level; a positive ttl reverts it to the configured level once it expires
+func (c *LogLevelController) Set(level slog.Level, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.level.Set(level)
+	c.scheduleRevert(&c.levelRevert, ttl, func() {
+		c.level.Set(c.configured)
+		slog.Info("Log level reverted", "level", LevelName(c.configured))
+	})
+}
+
+// SetOverrides replaces the level overrides; a positive ttl reverts them once it expires
+func (c *LogLevelController) SetOverrides(rules []LevelRule, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.overrides.Set(rules)
+	c.scheduleRevert(&c.overridesRevert, ttl, func() {
+		c.overrides.Set(c.configuredRules)
+		slog.Info("Log level overrides reverted", "overrides", len(c.configuredRules))
+	})
+}
+
+// Reset replaces the configured level and overrides, e.g. after the configuration is reloaded,
+// and cancels any temporary change
+func (c *LogLevelController) Reset(configured slog.Level, rules []LevelRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.levelRevert.stop()
+	c.overridesRevert.stop()
+	c.configured = configured
+	c.configuredRules = rules
+	c.level.Set(configured)
+	c.overrides.Set(rules)
+}
+
+// scheduleRevert must be called with mu held; revert runs with mu held once ttl expires
+func (c *LogLevelController) scheduleRevert(pending *pendingRevert, ttl time.Duration, revert func()) {
+	pending.stop()
+	if ttl <= 0 {
+		return
+	}
+
+	pending.at = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// A later change replaced this timer; leave its value alone
+		if pending.timer != timer {
+			return
+		}
+		pending.timer = nil
+		pending.at = time.Time{}
+		revert()
+	})
+	pending.timer = timer
+}
@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+	assert.Equal(t, "debug", LevelName(level))
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLogLevelController_SharedByHandlers(t *testing.T) {
+	controller := NewLogLevelController(slog.LevelInfo, nil)
+
+	var filtered, console bytes.Buffer
+	logger := slog.New(NewMultiHandler(
+		NewLevelFilterHandler(slog.NewTextHandler(&filtered, &slog.HandlerOptions{Level: slog.LevelDebug}), controller.Leveler()),
+		slog.NewTextHandler(&console, &slog.HandlerOptions{Level: controller.Leveler()}),
+	))
+
+	logger.Debug("hidden")
+	controller.Set(slog.LevelDebug, 0)
+	logger.Debug("visible")
+
+	for _, output := range []string{filtered.String(), console.String()} {
+		assert.NotContains(t, output, "hidden")
+		assert.Contains(t, output, "visible")
+	}
+}
+
+func TestLogLevelController_RevertsAfterTTL(t *testing.T) {
+	controller := NewLogLevelController(slog.LevelInfo, nil)
+
+	controller.Set(slog.LevelDebug, 20*time.Millisecond)
+	controller.SetOverrides([]LevelRule{{Kind: LevelRuleLogger, Key: "services", Level: slog.LevelDebug}}, 20*time.Millisecond)
+	level, revertAt := controller.Level()
+	assert.Equal(t, slog.LevelDebug, level)
+	assert.False(t, revertAt.IsZero())
+	assert.Len(t, controller.Overrides().Rules(), 1)
+
+	assert.Eventually(t, func() bool {
+		level, revertAt := controller.Level()
+		return level == slog.LevelInfo && revertAt.IsZero() && len(controller.Overrides().Rules()) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestLogLevelController_RevertsLevelAndOverridesSeparately(t *testing.T) {
+	controller := NewLogLevelController(slog.LevelInfo, nil)
+
+	controller.Set(slog.LevelDebug, 20*time.Millisecond)
+	// Changing the overrides without a TTL must not cancel the level's revert
+	controller.SetOverrides([]LevelRule{{Kind: LevelRuleLogger, Key: "services", Level: slog.LevelDebug}}, 0)
+
+	_, overridesRevertAt := controller.OverrideRules()
+	assert.True(t, overridesRevertAt.IsZero())
+	assert.Eventually(t, func() bool {
+		level, _ := controller.Level()
+		return level == slog.LevelInfo
+	}, time.Second, 5*time.Millisecond)
+
+	rules, _ := controller.OverrideRules()
+	assert.Len(t, rules, 1, "the overrides had no TTL and must be kept")
+}
+
+func TestLogLevelController_ResetCancelsRevert(t *testing.T) {
+	controller := NewLogLevelController(slog.LevelInfo, nil)
+
+	controller.Set(slog.LevelDebug, 20*time.Millisecond)
+	controller.Reset(slog.LevelWarn, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	level, revertAt := controller.Level()
+	assert.Equal(t, slog.LevelWarn, level)
+	assert.True(t, revertAt.IsZero())
+	assert.False(t, slog.New(NewLevelFilterHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), controller.Leveler())).
+		Enabled(context.Background(), slog.LevelInfo))
+}
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Log attribute keys of the request-scoped logger
+const (
+	LogKeyRoute     = "route"
+	LogKeyRequestID = "request_id"
+	LogKeyUserID    = "user_id"
+	LogKeyTraceID   = "trace_id"
+	LogKeySpanID    = "span_id"
+)
+
+type loggerContextKey struct{}
+
+// ContextWithLogger stores a logger for FromContext to return
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// ContextWithLogAttrs stores the logger of ctx extended with args, in the form slog.Logger.With takes
+func ContextWithLogAttrs(ctx context.Context, args ...any) context.Context {
+	return ContextWithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+// FromContext returns the request-scoped logger stored in ctx, falling back to slog.Default()
+// outside a request. Pass ctx to its Context methods as well, so span IDs and level overrides
+// follow the current span and route.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+
+	var buf bytes.Buffer
+	ctx := ContextWithLogger(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+	ctx = ContextWithLogAttrs(ctx, LogKeyUserID, "user123")
+
+	FromContext(ctx).InfoContext(ctx, "cart processed")
+	assert.Contains(t, buf.String(), `msg="cart processed" user_id=user123`)
+}
+
+func TestFromContext_NoDuplicateCorrelationAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRequestIDHandler(NewTraceContextHandler(slog.NewTextHandler(&buf, nil))))
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x0a},
+		SpanID:  trace.SpanID{0x0b},
+	})
+	ctx := ContextWithRequestID(trace.ContextWithSpanContext(context.Background(), spanContext), "req-1")
+	ctx = ContextWithLogger(ctx, logger.With(LogKeyRequestID, "req-1", LogKeyTraceID, spanContext.TraceID().String()))
+
+	FromContext(ctx).InfoContext(ctx, "cart processed")
+
+	output := buf.String()
+	assert.Equal(t, 1, strings.Count(output, "request_id=req-1"))
+	assert.Equal(t, 1, strings.Count(output, "trace_id="+spanContext.TraceID().String()))
+	assert.Contains(t, output, "span_id="+spanContext.SpanID().String())
+}
@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// instrumentKinds maps config names to SDK instrument kinds
+var instrumentKinds = map[string]sdkmetric.InstrumentKind{
+	"counter":                 sdkmetric.InstrumentKindCounter,
+	"updowncounter":           sdkmetric.InstrumentKindUpDownCounter,
+	"histogram":               sdkmetric.InstrumentKindHistogram,
+	"gauge":                   sdkmetric.InstrumentKindGauge,
+	"observablecounter":       sdkmetric.InstrumentKindObservableCounter,
+	"observableupdowncounter": sdkmetric.InstrumentKindObservableUpDownCounter,
+	"observablegauge":         sdkmetric.InstrumentKindObservableGauge,
+}
+
+func parseTemporality(value string) (metricdata.Temporality, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "cumulative":
+		return metricdata.CumulativeTemporality, nil
+	case "delta":
+		return metricdata.DeltaTemporality, nil
+	default:
+		return metricdata.Temporality(0), fmt.Errorf("unknown metric temporality %q", value)
+	}
+}
+
+// newTemporalitySelector builds a selector from a preference (cumulative, delta or lowmemory,
+// following OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE) and comma-separated
+// kind=temporality overrides such as "histogram=delta,updowncounter=cumulative"
+func newTemporalitySelector(preference, overrides string) (sdkmetric.TemporalitySelector, error) {
+	temporalities := map[sdkmetric.InstrumentKind]metricdata.Temporality{}
+	for _, kind := range instrumentKinds {
+		temporalities[kind] = metricdata.CumulativeTemporality
+	}
+
+	switch strings.ToLower(strings.TrimSpace(preference)) {
+	case "", "cumulative":
+	case "delta":
+		temporalities[sdkmetric.InstrumentKindCounter] = metricdata.DeltaTemporality
+		temporalities[sdkmetric.InstrumentKindObservableCounter] = metricdata.DeltaTemporality
+		temporalities[sdkmetric.InstrumentKindHistogram] = metricdata.DeltaTemporality
+	case "lowmemory":
+		temporalities[sdkmetric.InstrumentKindCounter] = metricdata.DeltaTemporality
+		temporalities[sdkmetric.InstrumentKindHistogram] = metricdata.DeltaTemporality
+	default:
+		return nil, fmt.Errorf("unknown metric temporality preference %q", preference)
+	}
+
+	for _, override := range strings.Split(overrides, ",") {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid metric temporality override %q", override)
+		}
+
+		kind, ok := instrumentKinds[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown instrument kind %q", name)
+		}
+
+		temporality, err := parseTemporality(value)
+		if err != nil {
+			return nil, err
+		}
+		temporalities[kind] = temporality
+	}
+
+	return func(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+		if temporality, ok := temporalities[kind]; ok {
+			return temporality
+		}
+		return metricdata.CumulativeTemporality
+	}, nil
+}
+
+// parseExemplarFilter maps OTEL_METRICS_EXEMPLAR_FILTER style values to SDK filters
+func parseExemplarFilter(value string) (exemplar.Filter, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "trace_based", "trace":
+		return exemplar.TraceBasedFilter, nil
+	case "always_on", "always":
+		return exemplar.AlwaysOnFilter, nil
+	case "always_off", "off":
+		return exemplar.AlwaysOffFilter, nil
+	default:
+		return nil, fmt.Errorf("unknown metric exemplar filter %q", value)
+	}
+}
@@ -0,0 +1,157 @@
+package telemetry
+
+import (
+	"context"
+	"fiber-api/schemas"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTemporalitySelector(t *testing.T) {
+	tests := []struct {
+		name       string
+		preference string
+		overrides  string
+		kind       sdkmetric.InstrumentKind
+		expected   metricdata.Temporality
+	}{
+		{"cumulative counter", "cumulative", "", sdkmetric.InstrumentKindCounter, metricdata.CumulativeTemporality},
+		{"delta counter", "delta", "", sdkmetric.InstrumentKindCounter, metricdata.DeltaTemporality},
+		{"delta up-down counter", "delta", "", sdkmetric.InstrumentKindUpDownCounter, metricdata.CumulativeTemporality},
+		{"lowmemory observable counter", "lowmemory", "", sdkmetric.InstrumentKindObservableCounter, metricdata.CumulativeTemporality},
+		{"lowmemory histogram", "lowmemory", "", sdkmetric.InstrumentKindHistogram, metricdata.DeltaTemporality},
+		{"override histogram", "cumulative", "histogram=delta", sdkmetric.InstrumentKindHistogram, metricdata.DeltaTemporality},
+		{"override leaves others", "cumulative", " histogram = delta ,", sdkmetric.InstrumentKindCounter, metricdata.CumulativeTemporality},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := newTemporalitySelector(tt.preference, tt.overrides)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selector(tt.kind))
+		})
+	}
+}
+
+func TestNewTemporalitySelector_Invalid(t *testing.T) {
+	_, err := newTemporalitySelector("sometimes", "")
+	assert.Error(t, err)
+
+	_, err = newTemporalitySelector("cumulative", "histogram")
+	assert.Error(t, err)
+
+	_, err = newTemporalitySelector("cumulative", "timer=delta")
+	assert.Error(t, err)
+
+	_, err = newTemporalitySelector("cumulative", "counter=eventually")
+	assert.Error(t, err)
+}
+
+func TestParseExemplarFilter(t *testing.T) {
+	for _, value := range []string{"", "trace_based", "always_on", "always", "always_off", "OFF"} {
+		_, err := parseExemplarFilter(value)
+		assert.NoError(t, err, value)
+	}
+
+	_, err := parseExemplarFilter("sometimes")
+	assert.Error(t, err)
+}
+
+func TestRecordHistogram_ExemplarFromSpanContext(t *testing.T) {
+	filter, err := parseExemplarFilter("trace_based")
+	require.NoError(t, err)
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(filter),
+	)
+	defer provider.Shutdown(context.Background())
+
+	telemetryProvider := &DefaultTelemetryProvider{meter: provider.Meter("test")}
+	exporter := telemetryProvider.GetMetricsExporter()
+
+	traceID := trace.TraceID{0x01, 0x02, 0x03}
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x04},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	exporter.RecordHistogram(ctx, schemas.HTTPRequestDurationSeconds, 0.25, nil)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	histogram, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 1)
+	require.Len(t, histogram.DataPoints[0].Exemplars, 1)
+	assert.Equal(t, traceID[:], histogram.DataPoints[0].Exemplars[0].TraceID)
+}
+
+func TestRegisterObservableGauges_SingleCallback(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	telemetryProvider := &DefaultTelemetryProvider{meter: provider.Meter("test")}
+	exporter := telemetryProvider.GetMetricsExporter()
+
+	calls := 0
+	err := exporter.RegisterObservableGauges([]string{"gauge.a", "gauge.b"},
+		func(ctx context.Context, observe func(string, float64, []attribute.KeyValue)) {
+			calls++
+			observe("gauge.a", 1, nil)
+			observe("gauge.b", 2, nil)
+			observe("gauge.unknown", 3, nil)
+		})
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	assert.Equal(t, 1, calls)
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	values := map[string]float64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		gauge, ok := m.Data.(metricdata.Gauge[float64])
+		require.True(t, ok)
+		require.Len(t, gauge.DataPoints, 1)
+		values[m.Name] = gauge.DataPoints[0].Value
+	}
+	assert.Equal(t, map[string]float64{"gauge.a": 1, "gauge.b": 2}, values)
+}
+
+func TestRegisterObservableCounter(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	telemetryProvider := &DefaultTelemetryProvider{meter: provider.Meter("test")}
+	exporter := telemetryProvider.GetMetricsExporter()
+
+	var total int64 = 3
+	require.NoError(t, exporter.RegisterObservableCounter("counter.total", func(ctx context.Context) int64 {
+		return total
+	}))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.True(t, sum.IsMonotonic)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(3), sum.DataPoints[0].Value)
+}
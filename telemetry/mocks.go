@@ -5,7 +5,9 @@ import (
 	"log/slog"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MockMetricsExporter is a mock implementation of MetricsExporter
@@ -27,22 +29,74 @@ func (m *MockMetricsExporter) RecordGauge(ctx context.Context, name string, valu
 	// No-op for testing
 }
 
+func (m *MockMetricsExporter) RecordUpDownCounter(ctx context.Context, name string, value int64, attributes []attribute.KeyValue) {
+	// No-op for testing
+}
+
+func (m *MockMetricsExporter) RegisterObservableGauges(names []string, callback ObservableGaugesCallback) error {
+	return nil // No-op for testing
+}
+
+func (m *MockMetricsExporter) RegisterObservableCounter(name string, callback ObservableCounterCallback) error {
+	return nil // No-op for testing
+}
+
 // MockTracesExporter is a mock implementation of TracesExporter
 type MockTracesExporter struct{}
 
-func (m *MockTracesExporter) StartSpan(ctx context.Context, spanName string) (context.Context, func()) {
-	return ctx, func() {}
+func (m *MockTracesExporter) StartSpan(ctx context.Context, spanName string, opts ...SpanOption) (context.Context, Span) {
+	return ctx, &MockSpan{}
 }
 
 func (m *MockTracesExporter) AddSpanEvent(ctx context.Context, eventName string, attributes []attribute.KeyValue) {
 	// No-op for testing
 }
 
+func (m *MockTracesExporter) SetAttributes(ctx context.Context, attributes []attribute.KeyValue) {
+	// No-op for testing
+}
+
+func (m *MockTracesExporter) RecordError(ctx context.Context, err error, attributes []attribute.KeyValue) {
+	// No-op for testing
+}
+
+func (m *MockTracesExporter) SetStatus(ctx context.Context, code codes.Code, description string) {
+	// No-op for testing
+}
+
+// MockSpan is a mock implementation of Span
+type MockSpan struct{}
+
+func (m *MockSpan) End() {
+	// No-op for testing
+}
+
+func (m *MockSpan) SetAttributes(attributes ...attribute.KeyValue) {
+	// No-op for testing
+}
+
+func (m *MockSpan) AddEvent(eventName string, attributes ...attribute.KeyValue) {
+	// No-op for testing
+}
+
+func (m *MockSpan) RecordError(err error, attributes ...attribute.KeyValue) {
+	// No-op for testing
+}
+
+func (m *MockSpan) SetStatus(code codes.Code, description string) {
+	// No-op for testing
+}
+
+func (m *MockSpan) SpanContext() trace.SpanContext {
+	return trace.SpanContext{}
+}
+
 // MockTelemetryProvider is a mock implementation of TelemetryProvider
 type MockTelemetryProvider struct {
 	mockMetricsExporter *MockMetricsExporter
 	mockTracesExporter  *MockTracesExporter
 	mockLogger          *slog.Logger
+	mockLogLevel        *LogLevelController
 }
 
 func NewMockTelemetryProvider() *MockTelemetryProvider {
@@ -50,6 +104,7 @@ func NewMockTelemetryProvider() *MockTelemetryProvider {
 		mockMetricsExporter: &MockMetricsExporter{},
 		mockTracesExporter:  &MockTracesExporter{},
 		mockLogger:          slog.New(slog.NewTextHandler(&mockWriter{}, &slog.HandlerOptions{})),
+		mockLogLevel:        NewLogLevelController(slog.LevelInfo, nil),
 	}
 }
 
@@ -69,6 +124,18 @@ func (m *MockTelemetryProvider) GetTracerProvider() *sdktrace.TracerProvider {
 	return nil // Not needed for testing
 }
 
+func (m *MockTelemetryProvider) GetLogLevel() *LogLevelController {
+	return m.mockLogLevel
+}
+
+func (m *MockTelemetryProvider) GetRedactor() *Redactor {
+	return nil // Redacts nothing
+}
+
+func (m *MockTelemetryProvider) ReopenLogs() error {
+	return nil // No-op for testing
+}
+
 func (m *MockTelemetryProvider) Shutdown(ctx context.Context) error {
 	return nil // No-op for testing
 }
@@ -2,18 +2,98 @@ package telemetry
 
 import (
 	"context"
+	"errors"
+	"fiber-api/schemas"
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// multiHandlerWarnAfter is the number of failures after which a child gets its stderr warning
+const multiHandlerWarnAfter = 3
+
+// ErrLogRecordDropped is reported for records an async child had no buffer space for
+var ErrLogRecordDropped = errors.New("log record dropped: async buffer full")
+
+// DropPolicy decides what an async child does when its buffer is full
+type DropPolicy int
+
+const (
+	// DropNewest discards the incoming record
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest buffered record to make room
+	DropOldest
+	// Block waits for buffer space, applying backpressure to the caller
+	Block
 )
 
-// MultiHandler sends log records to multiple handlers
+// ParseDropPolicy parses drop_newest, drop_oldest or block
+func ParseDropPolicy(name string) (DropPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "drop_newest":
+		return DropNewest, nil
+	case "drop_oldest":
+		return DropOldest, nil
+	case "block":
+		return Block, nil
+	default:
+		return DropNewest, fmt.Errorf("unknown log drop policy %q", name)
+	}
+}
+
+// AsyncOptions makes a child receive records through a buffer drained by its own goroutine
+type AsyncOptions struct {
+	BufferSize int
+	DropPolicy DropPolicy
+}
+
+// MultiHandlerChild is one destination of a MultiHandler
+type MultiHandlerChild struct {
+	// Name identifies the child in warnings and stats
+	Name    string
+	Handler slog.Handler
+	// Async delivers records in the background when set, so a slow child cannot delay the others
+	Async *AsyncOptions
+}
+
+// HandlerStats reports the failures of one MultiHandler child
+type HandlerStats struct {
+	Name    string
+	Errors  uint64
+	Dropped uint64
+}
+
+// MultiHandler sends log records to multiple handlers. Child errors are counted rather than
+// returned, with a one-time warning on stderr once a child keeps failing.
 type MultiHandler struct {
 	handlers []slog.Handler
+	sinks    []*childSink
 }
 
 func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
-	return &MultiHandler{
-		handlers: handlers,
+	children := make([]MultiHandlerChild, len(handlers))
+	for i, h := range handlers {
+		children[i] = MultiHandlerChild{Name: "handler" + strconv.Itoa(i), Handler: h}
 	}
+	return NewMultiHandlerWithChildren(children...)
+}
+
+// NewMultiHandlerWithChildren creates a MultiHandler with named and optionally async children
+func NewMultiHandlerWithChildren(children ...MultiHandlerChild) *MultiHandler {
+	m := &MultiHandler{}
+	for _, child := range children {
+		m.handlers = append(m.handlers, child.Handler)
+		m.sinks = append(m.sinks, newChildSink(child.Name, child.Async, os.Stderr))
+	}
+	return m
 }
 
 func (m *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
@@ -26,11 +106,12 @@ func (m *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
 }
 
 func (m *MultiHandler) Handle(ctx context.Context, record slog.Record) error {
-	for _, h := range m.handlers {
-		if err := h.Handle(ctx, record); err != nil {
-			// Log handler errors, but don't fail the entire operation
+	for i, h := range m.handlers {
+		// The record passed Enabled because some child wanted it; skip the ones that do not
+		if !h.Enabled(ctx, record.Level) {
 			continue
 		}
+		m.sinks[i].handle(ctx, h, record)
 	}
 	return nil
 }
@@ -40,7 +121,7 @@ func (m *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	for _, h := range m.handlers {
 		newHandlers = append(newHandlers, h.WithAttrs(attrs))
 	}
-	return &MultiHandler{handlers: newHandlers}
+	return &MultiHandler{handlers: newHandlers, sinks: m.sinks}
 }
 
 func (m *MultiHandler) WithGroup(name string) slog.Handler {
@@ -48,5 +129,209 @@ func (m *MultiHandler) WithGroup(name string) slog.Handler {
 	for _, h := range m.handlers {
 		newHandlers = append(newHandlers, h.WithGroup(name))
 	}
-	return &MultiHandler{handlers: newHandlers}
-}
\ No newline at end of file
+	return &MultiHandler{handlers: newHandlers, sinks: m.sinks}
+}
+
+// Stats returns the error and drop counts of every child
+func (m *MultiHandler) Stats() []HandlerStats {
+	stats := make([]HandlerStats, len(m.sinks))
+	for i, sink := range m.sinks {
+		stats[i] = HandlerStats{
+			Name:    sink.name,
+			Errors:  sink.errors.Load(),
+			Dropped: sink.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// Close delivers the records buffered for async children and stops their goroutines; later
+// records are delivered synchronously
+func (m *MultiHandler) Close(ctx context.Context) error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("log handler %s: %w", sink.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// asyncRecord is a record queued for an async child, with the derived handler it was sent to
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// childSink is the delivery state of one child, shared by every handler derived from the
+// MultiHandler through WithAttrs and WithGroup
+type childSink struct {
+	name     string
+	warnings io.Writer
+
+	errors  atomic.Uint64
+	dropped atomic.Uint64
+	warned  atomic.Bool
+
+	// queue is nil for synchronous children
+	queue      chan asyncRecord
+	dropPolicy DropPolicy
+	mu         sync.RWMutex
+	closed     bool
+	done       chan struct{}
+}
+
+func newChildSink(name string, async *AsyncOptions, warnings io.Writer) *childSink {
+	sink := &childSink{
+		name:     name,
+		warnings: warnings,
+	}
+	if async != nil {
+		sink.queue = make(chan asyncRecord, max(async.BufferSize, 1))
+		sink.dropPolicy = async.DropPolicy
+		sink.done = make(chan struct{})
+		go sink.run()
+	}
+	return sink
+}
+
+func (s *childSink) handle(ctx context.Context, h slog.Handler, record slog.Record) {
+	if s.queue == nil {
+		s.report(h.Handle(ctx, record))
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		s.report(h.Handle(ctx, record))
+		return
+	}
+
+	// The record outlives this call, and so may the context of a finished request
+	entry := asyncRecord{ctx: context.WithoutCancel(ctx), handler: h, record: snapshotRecord(record)}
+	switch s.dropPolicy {
+	case Block:
+		s.queue <- entry
+		return
+	case DropOldest:
+		for {
+			select {
+			case s.queue <- entry:
+				return
+			default:
+			}
+			select {
+			case <-s.queue:
+				s.drop()
+			default:
+			}
+		}
+	default:
+		select {
+		case s.queue <- entry:
+		default:
+			s.drop()
+		}
+	}
+}
+
+// snapshotRecord copies a record for delivery after the caller has moved on. Clone only copies
+// the attribute slice, so LogValuers are resolved now, while they still describe the state at
+// the time of the call, and other values of kind Any, which may be mutated by the caller, are
+// formatted to strings.
+func snapshotRecord(record slog.Record) slog.Record {
+	snapshot := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		snapshot.AddAttrs(snapshotAttr(attr))
+		return true
+	})
+	return snapshot
+}
+
+func snapshotAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		children := make([]slog.Attr, len(group))
+		for i, child := range group {
+			children[i] = snapshotAttr(child)
+		}
+		attr.Value = slog.GroupValue(children...)
+	case slog.KindAny:
+		attr.Value = slog.StringValue(attr.Value.String())
+	}
+	return attr
+}
+
+func (s *childSink) run() {
+	defer close(s.done)
+	for entry := range s.queue {
+		s.report(entry.handler.Handle(entry.ctx, entry.record))
+	}
+}
+
+func (s *childSink) close(ctx context.Context) error {
+	if s.queue == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *childSink) drop() {
+	s.dropped.Add(1)
+	s.report(ErrLogRecordDropped)
+}
+
+// report counts a failed delivery and warns on stderr once the child keeps failing; the
+// warning cannot go through slog, since this handler may be the one that is failing
+func (s *childSink) report(err error) {
+	if err == nil {
+		return
+	}
+	if s.errors.Add(1) >= multiHandlerWarnAfter && s.warned.CompareAndSwap(false, true) {
+		fmt.Fprintf(s.warnings, "WARNING: log handler %s failed %d times, further failures are only counted: %v\n",
+			s.name, multiHandlerWarnAfter, err)
+	}
+}
+
+// RegisterLogHandlerMetrics reports the error and drop counts of every MultiHandler child
+func RegisterLogHandlerMetrics(meter metric.Meter, m *MultiHandler) (metric.Registration, error) {
+	errorsTotal, err := meter.Int64ObservableCounter(schemas.LogHandlerErrorsTotal,
+		metric.WithDescription("Log records a handler failed to deliver, including dropped ones"),
+		metric.WithUnit("{record}"))
+	if err != nil {
+		return nil, err
+	}
+
+	droppedTotal, err := meter.Int64ObservableCounter(schemas.LogHandlerDroppedTotal,
+		metric.WithDescription("Log records dropped because an async handler buffer was full"),
+		metric.WithUnit("{record}"))
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		for _, stats := range m.Stats() {
+			attributes := metric.WithAttributes(attribute.String("handler", stats.Name))
+			observer.ObserveInt64(errorsTotal, int64(stats.Errors), attributes)
+			observer.ObserveInt64(droppedTotal, int64(stats.Dropped), attributes)
+		}
+		return nil
+	}, errorsTotal, droppedTotal)
+}
@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHandler collects messages, optionally blocking or failing each Handle call
+type recordingHandler struct {
+	level   slog.Level
+	err     error
+	release chan struct{}
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (h *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.release != nil {
+		<-h.release
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, record.Message)
+	return h.err
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler { return h }
+
+func (h *recordingHandler) Messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.messages...)
+}
+
+func TestMultiHandler_EnabledPerChild(t *testing.T) {
+	debug := &recordingHandler{level: slog.LevelDebug}
+	info := &recordingHandler{level: slog.LevelInfo}
+	logger := slog.New(NewMultiHandler(debug, info))
+
+	logger.Debug("debug only")
+	logger.Info("both")
+
+	assert.Equal(t, []string{"debug only", "both"}, debug.Messages())
+	assert.Equal(t, []string{"both"}, info.Messages())
+}
+
+func TestMultiHandler_CountsErrorsAndWarnsOnce(t *testing.T) {
+	failing := &recordingHandler{err: errors.New("exporter unavailable")}
+	healthy := &recordingHandler{}
+	multi := NewMultiHandlerWithChildren(
+		MultiHandlerChild{Name: "otlp", Handler: failing},
+		MultiHandlerChild{Name: "console", Handler: healthy},
+	)
+	var warnings bytes.Buffer
+	multi.sinks[0].warnings = &warnings
+
+	logger := slog.New(multi).With("derived", true)
+	for range 5 {
+		logger.Info("message")
+	}
+
+	assert.Len(t, healthy.Messages(), 5, "a failing child must not affect the others")
+	assert.Equal(t, []HandlerStats{
+		{Name: "otlp", Errors: 5},
+		{Name: "console"},
+	}, multi.Stats())
+	assert.Equal(t, 1, strings.Count(warnings.String(), "WARNING: log handler otlp"))
+	assert.Contains(t, warnings.String(), "exporter unavailable")
+}
+
+func TestMultiHandler_AsyncDoesNotDelayOtherChildren(t *testing.T) {
+	slow := &recordingHandler{release: make(chan struct{})}
+	console := &recordingHandler{}
+	multi := NewMultiHandlerWithChildren(
+		MultiHandlerChild{Name: "otlp", Handler: slow, Async: &AsyncOptions{BufferSize: 10}},
+		MultiHandlerChild{Name: "console", Handler: console},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		slog.New(multi).Info("first")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("a blocked async child delayed the caller")
+	}
+	assert.Equal(t, []string{"first"}, console.Messages())
+
+	close(slow.release)
+	require.NoError(t, multi.Close(context.Background()))
+	assert.Equal(t, []string{"first"}, slow.Messages())
+
+	// After Close, records are delivered synchronously
+	slog.New(multi).Info("late")
+	assert.Equal(t, []string{"first", "late"}, slow.Messages())
+}
+
+func TestMultiHandler_AsyncDropPolicies(t *testing.T) {
+	for _, tt := range []struct {
+		policy   DropPolicy
+		expected []string
+	}{
+		{policy: DropNewest, expected: []string{"blocked", "1", "2"}},
+		{policy: DropOldest, expected: []string{"blocked", "3", "4"}},
+	} {
+		slow := &recordingHandler{release: make(chan struct{})}
+		multi := NewMultiHandlerWithChildren(
+			MultiHandlerChild{Name: "otlp", Handler: slow, Async: &AsyncOptions{BufferSize: 2, DropPolicy: tt.policy}},
+		)
+		multi.sinks[0].warnings = &bytes.Buffer{}
+		logger := slog.New(multi)
+
+		// The worker takes the first record and blocks on it, leaving the buffer to fill
+		logger.Info("blocked")
+		require.Eventually(t, func() bool { return len(multi.sinks[0].queue) == 0 }, time.Second, time.Millisecond)
+		for _, message := range []string{"1", "2", "3", "4"} {
+			logger.Info(message)
+		}
+
+		close(slow.release)
+		require.NoError(t, multi.Close(context.Background()))
+		assert.Equal(t, tt.expected, slow.Messages())
+		assert.Equal(t, uint64(2), multi.Stats()[0].Dropped)
+	}
+}
+
+// countingValuer reports how many times it was resolved
+type countingValuer struct{ calls *int }
+
+func (v countingValuer) LogValue() slog.Value {
+	*v.calls++
+	return slog.IntValue(*v.calls)
+}
+
+func TestMultiHandler_AsyncSnapshotsAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	release := make(chan struct{})
+	blocking := &recordingHandler{release: release}
+	multi := NewMultiHandlerWithChildren(
+		MultiHandlerChild{Name: "blocking", Handler: blocking, Async: &AsyncOptions{BufferSize: 10}},
+		MultiHandlerChild{Name: "text", Handler: slog.NewTextHandler(&buf, nil), Async: &AsyncOptions{BufferSize: 10}},
+	)
+
+	calls := 0
+	items := []string{"a"}
+	slog.New(multi).Info("cart", "items", items, slog.Group("user", "calls", countingValuer{&calls}))
+	// Changes after the call must not reach the queued record
+	items[0] = "changed"
+
+	close(release)
+	require.NoError(t, multi.Close(context.Background()))
+	assert.Contains(t, buf.String(), "items=[a] user.calls=")
+	assert.NotContains(t, buf.String(), "changed")
+	assert.Equal(t, 2, calls, "the valuer is resolved once per child, at the call")
+}
+
+func TestParseDropPolicy(t *testing.T) {
+	policy, err := ParseDropPolicy("Drop_Oldest")
+	require.NoError(t, err)
+	assert.Equal(t, DropOldest, policy)
+
+	_, err = ParseDropPolicy("discard")
+	assert.Error(t, err)
+}
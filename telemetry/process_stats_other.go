@@ -0,0 +1,13 @@
+//go:build !unix
+
+package telemetry
+
+type processStats struct {
+	openFDs          int64
+	userCPUSeconds   float64
+	systemCPUSeconds float64
+}
+
+func readProcessStats() (processStats, bool) {
+	return processStats{}, false
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package telemetry
+
+import (
+	"os"
+	"syscall"
+)
+
+type processStats struct {
+	openFDs          int64
+	userCPUSeconds   float64
+	systemCPUSeconds float64
+}
+
+func readProcessStats() (processStats, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return processStats{}, false
+	}
+
+	return processStats{
+		openFDs:          countOpenFDs(),
+		userCPUSeconds:   timevalSeconds(usage.Utime),
+		systemCPUSeconds: timevalSeconds(usage.Stime),
+	}, true
+}
+
+// countOpenFDs returns -1 when the platform exposes no per-process descriptor directory
+func countOpenFDs() int64 {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		entries, err := os.ReadDir(dir)
+		if err == nil && len(entries) > 0 {
+			// The listing includes the descriptor ReadDir opened on the directory itself
+			return int64(len(entries) - 1)
+		}
+	}
+	return -1
+}
+
+func timevalSeconds(tv syscall.Timeval) float64 {
+	return float64(tv.Sec) + float64(tv.Usec)/1e6
+}
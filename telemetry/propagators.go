@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// newPropagator composes propagators from a comma-separated OTEL_PROPAGATORS style list.
+// Extraction runs in the order given, so later formats win when several headers are present.
+func newPropagator(names string) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	seen := map[string]bool{}
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch name {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			propagators = append(propagators, jaeger.Jaeger{})
+		case "xray":
+			propagators = append(propagators, xray.Propagator{})
+		case "none":
+			return propagation.NewCompositeTextMapPropagator(), nil
+		default:
+			return nil, fmt.Errorf("unknown propagator %q", name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPropagator_Fields(t *testing.T) {
+	tests := []struct {
+		names    string
+		expected []string
+	}{
+		{"tracecontext,baggage", []string{"traceparent", "tracestate", "baggage"}},
+		{"b3", []string{"b3"}},
+		{"b3multi", []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"}},
+		{"jaeger", []string{"uber-trace-id"}},
+		{"xray", []string{"X-Amzn-Trace-Id"}},
+		{" tracecontext , tracecontext ", []string{"traceparent", "tracestate"}},
+		{"none", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.names, func(t *testing.T) {
+			propagator, err := newPropagator(tt.names)
+			require.NoError(t, err)
+			for _, field := range tt.expected {
+				assert.Contains(t, propagator.Fields(), field)
+			}
+			if tt.expected == nil {
+				assert.Empty(t, propagator.Fields())
+			}
+		})
+	}
+}
+
+func TestNewPropagator_Unknown(t *testing.T) {
+	_, err := newPropagator("tracecontext,zipkin")
+	assert.Error(t, err)
+}
+
+func TestNewPropagator_ExtractsB3AndJaeger(t *testing.T) {
+	propagator, err := newPropagator("tracecontext,b3,jaeger")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"b3":            "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+		"uber-trace-id": "80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:1",
+	}
+
+	for header, value := range tests {
+		t.Run(header, func(t *testing.T) {
+			headers := http.Header{}
+			headers.Set(header, value)
+
+			ctx := propagator.Extract(context.Background(), propagation.HeaderCarrier(headers))
+			spanContext := trace.SpanContextFromContext(ctx)
+
+			assert.Equal(t, "80f198ee56343ba864fe8b2a57d3eff7", spanContext.TraceID().String())
+			assert.Equal(t, "e457b5a2e4d86bd1", spanContext.SpanID().String())
+			assert.True(t, spanContext.IsSampled())
+		})
+	}
+}
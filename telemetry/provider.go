@@ -5,19 +5,19 @@ import (
 	"fiber-api/config"
 	"fiber-api/schemas"
 	"log/slog"
+	"math"
 	"os"
 	"strings"
-	"time"
 
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/metric"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
@@ -85,6 +85,12 @@ type DefaultTelemetryProvider struct {
 	meter          metric.Meter
 	tracer         trace.Tracer
 	logger         *slog.Logger
+	baggageMetrics *baggageMetricAttributes
+	redactor       *Redactor
+	logLevel       *LogLevelController
+	multiHandler   *MultiHandler
+	sampling       *SamplingHandler
+	logFile        *RotatingFile
 }
 
 func NewTelemetryProvider(serviceName, serviceVersion string) (TelemetryProvider, error) {
@@ -100,6 +106,17 @@ func NewTelemetryProvider(serviceName, serviceVersion string) (TelemetryProvider
 		return nil, err
 	}
 
+	cfg := config.GetConfig()
+
+	redactionRules, err := ParseRedactionRules(cfg.RedactionRules)
+	if err != nil {
+		return nil, err
+	}
+	redactor, err := NewRedactor(redactionRules, cfg.RedactionSalt)
+	if err != nil {
+		return nil, err
+	}
+
 	loggerProvider, err := setupLogs(ctx, res)
 	if err != nil {
 		return nil, err
@@ -110,43 +127,128 @@ func NewTelemetryProvider(serviceName, serviceVersion string) (TelemetryProvider
 		return nil, err
 	}
 
-	tracerProvider, err := setupTraces(ctx, res)
+	meter := meterProvider.Meter(serviceName)
+
+	tracerProvider, err := setupTraces(ctx, res, redactor, meter)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set up propagation for distributed tracing
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	propagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTextMapPropagator(propagator)
 
-	meter := meterProvider.Meter(serviceName)
 	tracer := tracerProvider.Tracer(serviceName)
 
-	// Get the configured log level
-	cfg := config.GetConfig()
-	logLevel := parseLogLevel(cfg.LogLevel)
+	// Register Go runtime and process metrics
+	if cfg.RuntimeMetricsEnabled {
+		if _, err := RegisterRuntimeMetrics(meter); err != nil {
+			return nil, err
+		}
+	}
 
-	// Create otelslog handler that bridges slog to OpenTelemetry
-	baseOtelHandler := otelslog.NewHandler(serviceName, otelslog.WithLoggerProvider(loggerProvider))
+	levelRules, err := ParseLevelRules(cfg.LogLevelOverrides)
+	if err != nil {
+		return nil, err
+	}
 
-	// Apply level filtering to otel handler
-	otelHandler := NewLevelFilterHandler(baseOtelHandler, logLevel)
+	// Share one adjustable level and set of overrides between both destinations
+	logLevel := NewLogLevelController(parseLogLevel(cfg.LogLevel), levelRules)
 
-	// Create a console handler with level filtering
-	consoleHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
+	// Create otelslog handler that bridges slog to OpenTelemetry
+	otelHandler := otelslog.NewHandler(serviceName, otelslog.WithLoggerProvider(loggerProvider))
+
+	// Create a console handler in the configured format with trace correlation; levels are
+	// filtered in front of both destinations, so it must accept anything an override enables
+	consoleHandler, err := NewConsoleHandler(os.Stdout, ConsoleOptions{
+		Format:    cfg.LogFormat,
+		AddSource: cfg.LogSource,
+		Service:   serviceName,
+		Level:     slog.Level(math.MinInt),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Send to every destination; the OTLP child can be made async so a slow exporter cannot
+	// delay console output
+	otlpChild := MultiHandlerChild{Name: "otlp", Handler: otelHandler}
+	if cfg.LogOTLPAsync {
+		dropPolicy, err := ParseDropPolicy(cfg.LogAsyncDropPolicy)
+		if err != nil {
+			return nil, err
+		}
+		otlpChild.Async = &AsyncOptions{BufferSize: cfg.LogAsyncBufferSize, DropPolicy: dropPolicy}
+	}
+	children := []MultiHandlerChild{otlpChild, {Name: "console", Handler: consoleHandler}}
+
+	// Optionally write to a rotating file as well, for hosts without a log shipper
+	var logFile *RotatingFile
+	if cfg.LogFilePath != "" {
+		logFile, err = NewRotatingFile(RotatingFileOptions{
+			Path:       cfg.LogFilePath,
+			MaxSize:    int64(cfg.LogFileMaxSizeMB) << 20,
+			MaxAge:     cfg.LogFileMaxAge,
+			MaxBackups: cfg.LogFileMaxBackups,
+			Compress:   cfg.LogFileCompress,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fileHandler, err := NewConsoleHandler(logFile, ConsoleOptions{
+			Format:    cfg.LogFileFormat,
+			AddSource: cfg.LogSource,
+			Service:   serviceName,
+			Level:     slog.Level(math.MinInt),
+		})
+		if err != nil {
+			logFile.Close()
+			return nil, err
+		}
+		children = append(children, MultiHandlerChild{Name: "file", Handler: fileHandler})
+	}
 
-	// Create a custom handler that sends to both destinations
-	multiHandler := NewMultiHandler(otelHandler, consoleHandler)
+	multiHandler := NewMultiHandlerWithChildren(children...)
+	if _, err := RegisterLogHandlerMetrics(meter, multiHandler); err != nil {
+		return nil, err
+	}
 
-	logger := slog.New(multiHandler)
+	// Redact sensitive attributes before they reach either destination
+	redactingHandler := NewRedactingHandler(multiHandler, redactor)
+
+	// Add allow-listed baggage from the record context, ahead of redaction
+	baggageHandler := NewBaggageHandler(redactingHandler, NewBaggageFilter(cfg.BaggageAllowedKeys))
+
+	// Add the request ID from the record context
+	requestIDHandler := NewRequestIDHandler(baggageHandler)
+
+	// Thin out log storms of identical records
+	var sampledHandler slog.Handler = requestIDHandler
+	var samplingHandler *SamplingHandler
+	if cfg.LogSamplingEnabled {
+		samplingHandler = NewSamplingHandler(requestIDHandler, SamplingOptions{
+			Window:            cfg.LogSamplingWindow,
+			First:             cfg.LogSamplingFirst,
+			Thereafter:        cfg.LogSamplingThereafter,
+			KeepSampledTraces: cfg.LogSamplingKeepSampledTraces,
+		})
+		sampledHandler = samplingHandler
+	}
+
+	// Apply the global level and per-logger and per-route overrides before any other work
+	logger := slog.New(NewLevelFilterHandlerWithOverrides(sampledHandler, logLevel.Leveler(), logLevel.Overrides()))
 
 	// Set the default slog logger to use our multi-handler
 	slog.SetDefault(logger)
 
+	var baggageMetrics *baggageMetricAttributes
+	if metricFilter := NewBaggageFilter(cfg.BaggageMetricKeys); !metricFilter.Empty() {
+		baggageMetrics = newBaggageMetricAttributes(metricFilter, cfg.BaggageMetricMaxValues)
+	}
+
 	return &DefaultTelemetryProvider{
 		loggerProvider: loggerProvider,
 		meterProvider:  meterProvider,
@@ -154,6 +256,12 @@ func NewTelemetryProvider(serviceName, serviceVersion string) (TelemetryProvider
 		meter:          meter,
 		tracer:         tracer,
 		logger:         logger,
+		baggageMetrics: baggageMetrics,
+		redactor:       redactor,
+		logLevel:       logLevel,
+		multiHandler:   multiHandler,
+		sampling:       samplingHandler,
+		logFile:        logFile,
 	}, nil
 }
 
@@ -169,9 +277,9 @@ func (p *DefaultTelemetryProvider) GetMetricsExporter() MetricsExporter {
 		slog.Error("Failed to create http_request_duration_seconds histogram", "error", err)
 	}
 
-	activeRequestsGauge, err := p.meter.Float64Gauge(schemas.HTTPActiveRequests)
+	activeRequestsCounter, err := p.meter.Int64UpDownCounter(schemas.HTTPActiveRequests)
 	if err != nil {
-		slog.Error("Failed to create http_active_requests gauge", "error", err)
+		slog.Error("Failed to create http_active_requests up-down counter", "error", err)
 	}
 
 	errorsCounter, err := p.meter.Int64Counter(schemas.ErrorsTotal)
@@ -179,11 +287,6 @@ func (p *DefaultTelemetryProvider) GetMetricsExporter() MetricsExporter {
 		slog.Error("Failed to create errors_total counter", "error", err)
 	}
 
-	cartItemsGauge, err := p.meter.Float64Gauge(schemas.CartCurrentItems)
-	if err != nil {
-		slog.Error("Failed to create cart_current_items gauge", "error", err)
-	}
-
 	cartRequestsCounter, err := p.meter.Int64Counter(schemas.CartRequestsTotal)
 	if err != nil {
 		slog.Error("Failed to create cart_requests_total counter", "error", err)
@@ -194,15 +297,28 @@ func (p *DefaultTelemetryProvider) GetMetricsExporter() MetricsExporter {
 		slog.Error("Failed to create cart_items_per_request histogram", "error", err)
 	}
 
+	httpClientRequestsCounter, err := p.meter.Int64Counter(schemas.HTTPClientRequestsTotal)
+	if err != nil {
+		slog.Error("Failed to create http_client_requests_total counter", "error", err)
+	}
+
+	httpClientDurationHistogram, err := p.meter.Float64Histogram(schemas.HTTPClientDurationSeconds)
+	if err != nil {
+		slog.Error("Failed to create http_client_request_duration_seconds histogram", "error", err)
+	}
+
 	return &DefaultMetricsExporter{
 		meter:                        p.meter,
 		httpRequestsCounter:          httpRequestsCounter,
 		httpDurationHistogram:        httpDurationHistogram,
-		activeRequestsGauge:          activeRequestsGauge,
+		activeRequestsCounter:        activeRequestsCounter,
 		errorsCounter:                errorsCounter,
-		cartItemsGauge:               cartItemsGauge,
 		cartRequestsCounter:          cartRequestsCounter,
 		cartItemsPerRequestHistogram: cartItemsPerRequestHistogram,
+		httpClientRequestsCounter:    httpClientRequestsCounter,
+		httpClientDurationHistogram:  httpClientDurationHistogram,
+		baggage:                      p.baggageMetrics,
+		redactor:                     p.redactor,
 	}
 }
 
@@ -211,14 +327,47 @@ func (p *DefaultTelemetryProvider) GetLogger() *slog.Logger {
 }
 
 func (p *DefaultTelemetryProvider) GetTracesExporter() TracesExporter {
-	return &DefaultTracesExporter{tracer: p.tracer}
+	return NewTracesExporter(p.tracer)
 }
 
 func (p *DefaultTelemetryProvider) GetTracerProvider() *sdktrace.TracerProvider {
 	return p.tracerProvider
 }
 
+func (p *DefaultTelemetryProvider) GetLogLevel() *LogLevelController {
+	return p.logLevel
+}
+
+// GetRedactor returns the redactor applied to spans, logs and metrics, for other outputs such as
+// the access log
+func (p *DefaultTelemetryProvider) GetRedactor() *Redactor {
+	return p.redactor
+}
+
+// ReopenLogs reopens the log file, e.g. after logrotate moved it away; it is a no-op without one
+func (p *DefaultTelemetryProvider) ReopenLogs() error {
+	if p.logFile == nil {
+		return nil
+	}
+	return p.logFile.Reopen()
+}
+
 func (p *DefaultTelemetryProvider) Shutdown(ctx context.Context) error {
+	// Report suppressed records, then hand buffered records to the logger provider before it flushes
+	if p.sampling != nil {
+		if err := p.sampling.Flush(ctx); err != nil {
+			slog.Error("Failed to flush log sampling summaries", "error", err)
+		}
+	}
+	if err := p.multiHandler.Close(ctx); err != nil {
+		slog.Error("Failed to flush async log handlers", "error", err)
+	}
+	if p.logFile != nil {
+		if err := p.logFile.Close(); err != nil {
+			slog.Error("Failed to close log file", "error", err)
+		}
+	}
+
 	if err := p.loggerProvider.Shutdown(ctx); err != nil {
 		slog.Error("Failed to shutdown logger provider", "error", err)
 		return err
@@ -241,11 +390,19 @@ type DefaultMetricsExporter struct {
 	meter                        metric.Meter
 	httpRequestsCounter          metric.Int64Counter
 	httpDurationHistogram        metric.Float64Histogram
-	activeRequestsGauge          metric.Float64Gauge
+	activeRequestsCounter        metric.Int64UpDownCounter
 	errorsCounter                metric.Int64Counter
-	cartItemsGauge               metric.Float64Gauge
 	cartRequestsCounter          metric.Int64Counter
 	cartItemsPerRequestHistogram metric.Float64Histogram
+	httpClientRequestsCounter    metric.Int64Counter
+	httpClientDurationHistogram  metric.Float64Histogram
+	baggage                      *baggageMetricAttributes
+	redactor                     *Redactor
+}
+
+// attributes adds baggage to the recorded attributes and then redacts them
+func (e *DefaultMetricsExporter) attributes(ctx context.Context, attributes []attribute.KeyValue) []attribute.KeyValue {
+	return e.redactor.Attributes(e.baggage.enrich(ctx, attributes))
 }
 
 func (e *DefaultMetricsExporter) RecordMetric(ctx context.Context, metricName string, value interface{}, attributes []attribute.KeyValue) {
@@ -267,6 +424,8 @@ func (e *DefaultMetricsExporter) RecordCounter(ctx context.Context, name string,
 		counter = e.errorsCounter
 	case schemas.CartRequestsTotal:
 		counter = e.cartRequestsCounter
+	case schemas.HTTPClientRequestsTotal:
+		counter = e.httpClientRequestsCounter
 	default:
 		// Fallback to creating new counter
 		var err error
@@ -277,7 +436,7 @@ func (e *DefaultMetricsExporter) RecordCounter(ctx context.Context, name string,
 		}
 	}
 
-	counter.Add(ctx, value, metric.WithAttributes(attributes...))
+	counter.Add(ctx, value, metric.WithAttributes(e.attributes(ctx, attributes)...))
 
 }
 
@@ -288,6 +447,8 @@ func (e *DefaultMetricsExporter) RecordHistogram(ctx context.Context, name strin
 		histogram = e.httpDurationHistogram
 	case schemas.CartItemsPerRequest:
 		histogram = e.cartItemsPerRequestHistogram
+	case schemas.HTTPClientDurationSeconds:
+		histogram = e.httpClientDurationHistogram
 	default:
 		// Fallback to creating new histogram
 		var err error
@@ -298,7 +459,7 @@ func (e *DefaultMetricsExporter) RecordHistogram(ctx context.Context, name strin
 		}
 	}
 
-	histogram.Record(ctx, value, metric.WithAttributes(attributes...))
+	histogram.Record(ctx, value, metric.WithAttributes(e.attributes(ctx, attributes)...))
 
 }
 
@@ -309,16 +470,81 @@ func (e *DefaultMetricsExporter) RecordGauge(ctx context.Context, name string, v
 		return
 	}
 
-	gauge.Record(ctx, value, metric.WithAttributes(attributes...))
+	gauge.Record(ctx, value, metric.WithAttributes(e.attributes(ctx, attributes)...))
+}
+
+func (e *DefaultMetricsExporter) RecordUpDownCounter(ctx context.Context, name string, value int64, attributes []attribute.KeyValue) {
+	var counter metric.Int64UpDownCounter
+	switch name {
+	case schemas.HTTPActiveRequests:
+		counter = e.activeRequestsCounter
+	default:
+		// Fallback to creating new up-down counter
+		var err error
+		counter, err = e.meter.Int64UpDownCounter(name)
+		if err != nil {
+			slog.Error("Failed to create up-down counter", "name", name, "error", err)
+			return
+		}
+	}
+
+	counter.Add(ctx, value, metric.WithAttributes(e.attributes(ctx, attributes)...))
+}
+
+// RegisterObservableGauges creates the named gauges and registers a single callback for all of
+// them, so values derived from one snapshot are observed together; unknown names are ignored
+func (e *DefaultMetricsExporter) RegisterObservableGauges(names []string, callback ObservableGaugesCallback) error {
+	gauges := make(map[string]metric.Float64ObservableGauge, len(names))
+	instruments := make([]metric.Observable, 0, len(names))
+	for _, name := range names {
+		gauge, err := e.meter.Float64ObservableGauge(name)
+		if err != nil {
+			slog.Error("Failed to create observable gauge", "name", name, "error", err)
+			return err
+		}
+		gauges[name] = gauge
+		instruments = append(instruments, gauge)
+	}
+
+	_, err := e.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		callback(ctx, func(name string, value float64, attributes []attribute.KeyValue) {
+			if gauge, ok := gauges[name]; ok {
+				o.ObserveFloat64(gauge, value, metric.WithAttributes(attributes...))
+			}
+		})
+		return nil
+	}, instruments...)
+	if err != nil {
+		slog.Error("Failed to register observable gauge callback", "names", names, "error", err)
+	}
+	return err
+}
+
+// RegisterObservableCounter creates a monotonic counter whose cumulative value is read from
+// callback on every collection, for totals kept elsewhere such as evicted carts
+func (e *DefaultMetricsExporter) RegisterObservableCounter(name string, callback ObservableCounterCallback) error {
+	_, err := e.meter.Int64ObservableCounter(name, metric.WithInt64Callback(
+		func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(callback(ctx))
+			return nil
+		}))
+	if err != nil {
+		slog.Error("Failed to create observable counter", "name", name, "error", err)
+	}
+	return err
 }
 
 type DefaultTracesExporter struct {
 	tracer trace.Tracer
 }
 
-func (e *DefaultTracesExporter) StartSpan(ctx context.Context, spanName string) (context.Context, func()) {
-	ctx, span := e.tracer.Start(ctx, spanName)
-	return ctx, func() { span.End() }
+func NewTracesExporter(tracer trace.Tracer) *DefaultTracesExporter {
+	return &DefaultTracesExporter{tracer: tracer}
+}
+
+func (e *DefaultTracesExporter) StartSpan(ctx context.Context, spanName string, opts ...SpanOption) (context.Context, Span) {
+	ctx, span := e.tracer.Start(ctx, spanName, newSpanConfig(opts).startOptions()...)
+	return ctx, &defaultSpan{span: span}
 }
 
 func (e *DefaultTracesExporter) AddSpanEvent(ctx context.Context, eventName string, attributes []attribute.KeyValue) {
@@ -328,6 +554,56 @@ func (e *DefaultTracesExporter) AddSpanEvent(ctx context.Context, eventName stri
 	}
 }
 
+func (e *DefaultTracesExporter) SetAttributes(ctx context.Context, attributes []attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetAttributes(attributes...)
+	}
+}
+
+func (e *DefaultTracesExporter) RecordError(ctx context.Context, err error, attributes []attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.RecordError(err, trace.WithAttributes(attributes...))
+	}
+}
+
+func (e *DefaultTracesExporter) SetStatus(ctx context.Context, code codes.Code, description string) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetStatus(code, description)
+	}
+}
+
+// defaultSpan adapts an OpenTelemetry span to the Span handle
+type defaultSpan struct {
+	span trace.Span
+}
+
+func (s *defaultSpan) End() {
+	s.span.End()
+}
+
+func (s *defaultSpan) SetAttributes(attributes ...attribute.KeyValue) {
+	s.span.SetAttributes(attributes...)
+}
+
+func (s *defaultSpan) AddEvent(eventName string, attributes ...attribute.KeyValue) {
+	s.span.AddEvent(eventName, trace.WithAttributes(attributes...))
+}
+
+func (s *defaultSpan) RecordError(err error, attributes ...attribute.KeyValue) {
+	s.span.RecordError(err, trace.WithAttributes(attributes...))
+}
+
+func (s *defaultSpan) SetStatus(code codes.Code, description string) {
+	s.span.SetStatus(code, description)
+}
+
+func (s *defaultSpan) SpanContext() trace.SpanContext {
+	return s.span.SpanContext()
+}
+
 func setupLogs(ctx context.Context, res *resource.Resource) (*log.LoggerProvider, error) {
 	var exporterOptions []otlploggrpc.Option
 	cfg := config.GetConfig()
@@ -371,6 +647,13 @@ func setupMetrics(ctx context.Context, res *resource.Resource) (*sdkmetric.Meter
 
 	}
 
+	temporalitySelector, err := newTemporalitySelector(cfg.MetricTemporality, cfg.MetricTemporalityOverrides)
+	if err != nil {
+		slog.Error("Invalid metric temporality configuration", "error", err)
+		return nil, err
+	}
+	exporterOptions = append(exporterOptions, otlpmetricgrpc.WithTemporalitySelector(temporalitySelector))
+
 	baseExporter, err := otlpmetricgrpc.New(ctx, exporterOptions...)
 	if err != nil {
 		slog.Error("Failed to create metrics exporter", "error", err)
@@ -380,14 +663,22 @@ func setupMetrics(ctx context.Context, res *resource.Resource) (*sdkmetric.Meter
 	// Wrap with logging exporter to track export attempts
 	loggingExporter := &LoggingMetricExporter{exporter: baseExporter}
 
-	// Use a fast 1-second export interval for testing
 	reader := sdkmetric.NewPeriodicReader(loggingExporter,
-		sdkmetric.WithInterval(1*time.Second),
+		sdkmetric.WithInterval(cfg.MetricExportInterval),
+		sdkmetric.WithTimeout(cfg.MetricExportTimeout),
 	)
 
+	// Exemplars attach sampled trace IDs to metric points recorded with a span in context
+	exemplarFilter, err := parseExemplarFilter(cfg.MetricExemplarFilter)
+	if err != nil {
+		slog.Error("Invalid metric exemplar filter", "error", err)
+		return nil, err
+	}
+
 	provider := sdkmetric.NewMeterProvider(
 		sdkmetric.WithResource(res),
 		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(exemplarFilter),
 	)
 
 	otel.SetMeterProvider(provider)
@@ -395,7 +686,7 @@ func setupMetrics(ctx context.Context, res *resource.Resource) (*sdkmetric.Meter
 	return provider, nil
 }
 
-func setupTraces(ctx context.Context, res *resource.Resource) (*sdktrace.TracerProvider, error) {
+func setupTraces(ctx context.Context, res *resource.Resource, redactor *Redactor, meter metric.Meter) (*sdktrace.TracerProvider, error) {
 	var exporterOptions []otlptracegrpc.Option
 	cfg := config.GetConfig()
 
@@ -414,10 +705,32 @@ func setupTraces(ctx context.Context, res *resource.Resource) (*sdktrace.TracerP
 		return nil, err
 	}
 
-	provider := sdktrace.NewTracerProvider(
+	// Redact span attributes on end, before the batcher queues them for export
+	var batcher sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	if !redactor.Empty() {
+		batcher = NewRedactingSpanProcessor(batcher, redactor)
+	}
+
+	providerOptions := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
-		sdktrace.WithBatcher(exporter),
-	)
+		sdktrace.WithSpanProcessor(batcher),
+	}
+
+	// Copy allow-listed baggage onto spans as they start
+	if filter := NewBaggageFilter(cfg.BaggageAllowedKeys); !filter.Empty() {
+		providerOptions = append(providerOptions, sdktrace.WithSpanProcessor(NewBaggageSpanProcessor(filter)))
+	}
+
+	// Derive RED metrics from every ended span
+	if cfg.SpanMetricsEnabled {
+		spanMetrics, err := NewSpanMetricsProcessor(meter)
+		if err != nil {
+			return nil, err
+		}
+		providerOptions = append(providerOptions, sdktrace.WithSpanProcessor(spanMetrics))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOptions...)
 
 	otel.SetTracerProvider(provider)
 	return provider, nil
@@ -0,0 +1,324 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RedactionAction is what happens to an attribute whose key matches a rule
+type RedactionAction int
+
+const (
+	RedactDrop RedactionAction = iota
+	RedactHash
+	RedactTruncate
+	// RedactStripQuery removes the query string and fragment from a URL or request target
+	RedactStripQuery
+)
+
+// RedactionRule applies an action to attribute keys matching a glob pattern such as "user_*"
+type RedactionRule struct {
+	Pattern string
+	Action  RedactionAction
+	// Length is the number of characters kept by RedactTruncate
+	Length int
+}
+
+// ParseRedactionRules parses comma-separated pattern=action rules, where action is drop, hash,
+// truncate:N or strip_query, e.g. "user_id=hash,user_agent.original=truncate:32,url.full=strip_query"
+func ParseRedactionRules(spec string) ([]RedactionRule, error) {
+	var rules []RedactionRule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, action, ok := strings.Cut(entry, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid redaction rule %q", entry)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+
+		rule := RedactionRule{Pattern: pattern}
+		action = strings.ToLower(strings.TrimSpace(action))
+		switch {
+		case action == "drop":
+			rule.Action = RedactDrop
+		case action == "hash":
+			rule.Action = RedactHash
+		case action == "strip_query":
+			rule.Action = RedactStripQuery
+		case strings.HasPrefix(action, "truncate:"):
+			length, err := strconv.Atoi(strings.TrimPrefix(action, "truncate:"))
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid truncate length in redaction rule %q", entry)
+			}
+			rule.Action = RedactTruncate
+			rule.Length = length
+		default:
+			return nil, fmt.Errorf("unknown redaction action in rule %q", entry)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Redactor rewrites attributes according to redaction rules before they leave the process
+type Redactor struct {
+	rules []RedactionRule
+	salt  string
+}
+
+// NewRedactor creates a redactor. The salt keeps hashed values from being reversed by lookup,
+// so hash rules are refused without one.
+func NewRedactor(rules []RedactionRule, salt string) (*Redactor, error) {
+	if salt == "" {
+		for _, rule := range rules {
+			if rule.Action == RedactHash {
+				return nil, fmt.Errorf("redaction rule %s=hash requires REDACTION_SALT", rule.Pattern)
+			}
+		}
+	}
+	return &Redactor{
+		rules: rules,
+		salt:  salt,
+	}, nil
+}
+
+// Empty reports whether the redactor has no rules and can be skipped
+func (r *Redactor) Empty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+func (r *Redactor) match(keys ...string) (RedactionRule, bool) {
+	for _, rule := range r.rules {
+		for _, key := range keys {
+			if matched, _ := path.Match(rule.Pattern, key); matched {
+				return rule, true
+			}
+		}
+	}
+	return RedactionRule{}, false
+}
+
+func (r *Redactor) hash(value string) string {
+	sum := sha256.Sum256([]byte(r.salt + value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// redactString returns the redacted value and whether the attribute should be kept
+func (r *Redactor) redactString(rule RedactionRule, value string) (string, bool) {
+	switch rule.Action {
+	case RedactHash:
+		return r.hash(value), true
+	case RedactTruncate:
+		if runes := []rune(value); len(runes) > rule.Length {
+			return string(runes[:rule.Length]), true
+		}
+		return value, true
+	case RedactStripQuery:
+		if i := strings.IndexAny(value, "?#"); i >= 0 {
+			return value[:i], true
+		}
+		return value, true
+	default:
+		return "", false
+	}
+}
+
+// Attributes returns attributes with every rule applied; the input slice is not modified
+func (r *Redactor) Attributes(attributes []attribute.KeyValue) []attribute.KeyValue {
+	if r.Empty() || len(attributes) == 0 {
+		return attributes
+	}
+
+	redacted := make([]attribute.KeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		rule, ok := r.match(string(attr.Key))
+		if !ok {
+			redacted = append(redacted, attr)
+			continue
+		}
+		if value, keep := r.redactString(rule, attr.Value.Emit()); keep {
+			redacted = append(redacted, attribute.String(string(attr.Key), value))
+		}
+	}
+	return redacted
+}
+
+// Value redacts a single value stored under key, reporting false when it must be dropped
+func (r *Redactor) Value(key, value string) (string, bool) {
+	if r.Empty() {
+		return value, true
+	}
+	rule, ok := r.match(key)
+	if !ok {
+		return value, true
+	}
+	return r.redactString(rule, value)
+}
+
+// slogAttr redacts a log attribute, matching rules against its key qualified by each suffix of
+// its enclosing group path, so "client.address" matches the key "address" inside group "client"
+func (r *Redactor) slogAttr(groups []string, attr slog.Attr) (slog.Attr, bool) {
+	if attr.Value.Kind() == slog.KindGroup {
+		nested := append(groups[:len(groups):len(groups)], attr.Key)
+		var children []any
+		for _, child := range attr.Value.Group() {
+			if redacted, keep := r.slogAttr(nested, child); keep {
+				children = append(children, redacted)
+			}
+		}
+		return slog.Group(attr.Key, children...), true
+	}
+
+	keys := make([]string, 0, len(groups)+1)
+	keys = append(keys, attr.Key)
+	for i := len(groups) - 1; i >= 0; i-- {
+		keys = append(keys, strings.Join(groups[i:], ".")+"."+attr.Key)
+	}
+
+	rule, ok := r.match(keys...)
+	if !ok {
+		return attr, true
+	}
+	value, keep := r.redactString(rule, attr.Value.Resolve().String())
+	return slog.String(attr.Key, value), keep
+}
+
+// RedactingSpanProcessor redacts span and event attributes before handing ended spans to the
+// wrapped processor, so attributes set at any point in the span's life are covered
+type RedactingSpanProcessor struct {
+	next     sdktrace.SpanProcessor
+	redactor *Redactor
+}
+
+func NewRedactingSpanProcessor(next sdktrace.SpanProcessor, redactor *Redactor) *RedactingSpanProcessor {
+	return &RedactingSpanProcessor{
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+func (p *RedactingSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *RedactingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	p.next.OnEnd(&redactedSpan{ReadOnlySpan: s, redactor: p.redactor})
+}
+
+func (p *RedactingSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *RedactingSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// redactedSpan overrides the attribute-bearing accessors of an ended span
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	redactor *Redactor
+}
+
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return s.redactor.Attributes(s.ReadOnlySpan.Attributes())
+}
+
+func (s *redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	redacted := make([]sdktrace.Event, len(events))
+	for i, event := range events {
+		event.Attributes = s.redactor.Attributes(event.Attributes)
+		redacted[i] = event
+	}
+	return redacted
+}
+
+func (s *redactedSpan) Links() []sdktrace.Link {
+	links := s.ReadOnlySpan.Links()
+	redacted := make([]sdktrace.Link, len(links))
+	for i, link := range links {
+		link.Attributes = s.redactor.Attributes(link.Attributes)
+		redacted[i] = link
+	}
+	return redacted
+}
+
+// RedactingHandler redacts log attributes, including those added through WithAttrs, before
+// they reach the wrapped handler
+type RedactingHandler struct {
+	handler  slog.Handler
+	redactor *Redactor
+	groups   []string
+}
+
+func NewRedactingHandler(handler slog.Handler, redactor *Redactor) *RedactingHandler {
+	return &RedactingHandler{
+		handler:  handler,
+		redactor: redactor,
+	}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.redactor.Empty() {
+		return h.handler.Handle(ctx, record)
+	}
+
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr, keep := h.redactor.slogAttr(h.groups, attr); keep {
+			redacted.AddAttrs(attr)
+		}
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if h.redactor.Empty() {
+		return &RedactingHandler{
+			handler:  h.handler.WithAttrs(attrs),
+			redactor: h.redactor,
+			groups:   h.groups,
+		}
+	}
+
+	var redacted []slog.Attr
+	for _, attr := range attrs {
+		if attr, keep := h.redactor.slogAttr(h.groups, attr); keep {
+			redacted = append(redacted, attr)
+		}
+	}
+	return &RedactingHandler{
+		handler:  h.handler.WithAttrs(redacted),
+		redactor: h.redactor,
+		groups:   h.groups,
+	}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{
+		handler:  h.handler.WithGroup(name),
+		redactor: h.redactor,
+		groups:   append(h.groups[:len(h.groups):len(h.groups)], name),
+	}
+}
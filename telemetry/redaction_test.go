@@ -0,0 +1,206 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fiber-api/config"
+	"fiber-api/schemas"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/contrib/otelfiber"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testUserID    = "user-42@example.com"
+	testUserAgent = "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+	testClientIP  = "203.0.113.7"
+)
+
+func newTestRedactor(t *testing.T) *Redactor {
+	rules, err := ParseRedactionRules("user_id=hash, userId=hash, user_agent*=truncate:7, client.*=drop, ip=drop")
+	require.NoError(t, err)
+	redactor, err := NewRedactor(rules, "salt")
+	require.NoError(t, err)
+	return redactor
+}
+
+func assertNoSensitiveValues(t *testing.T, output string) {
+	t.Helper()
+	assert.NotContains(t, output, testUserID)
+	assert.NotContains(t, output, testUserAgent)
+	assert.NotContains(t, output, testClientIP)
+}
+
+func TestParseRedactionRules(t *testing.T) {
+	rules, err := ParseRedactionRules("user_id=hash,user_agent.original=truncate:32,client.address=drop,url.full=strip_query")
+	require.NoError(t, err)
+	assert.Equal(t, []RedactionRule{
+		{Pattern: "user_id", Action: RedactHash},
+		{Pattern: "user_agent.original", Action: RedactTruncate, Length: 32},
+		{Pattern: "client.address", Action: RedactDrop},
+		{Pattern: "url.full", Action: RedactStripQuery},
+	}, rules)
+
+	for _, invalid := range []string{"user_id", "=hash", "user_id=mask", "user_id=truncate:x", "[=drop"} {
+		_, err := ParseRedactionRules(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestNewRedactor_HashRequiresSalt(t *testing.T) {
+	rules, err := ParseRedactionRules("user_id=hash")
+	require.NoError(t, err)
+	_, err = NewRedactor(rules, "")
+	assert.Error(t, err)
+
+	rules, err = ParseRedactionRules(config.DefaultRedactionRules)
+	require.NoError(t, err)
+	_, err = NewRedactor(rules, "")
+	assert.NoError(t, err, "the default rules must work without a salt")
+}
+
+func TestRedactor_Attributes(t *testing.T) {
+	redactor := newTestRedactor(t)
+	input := []attribute.KeyValue{
+		attribute.String("user_id", testUserID),
+		attribute.String("user_agent.original", testUserAgent),
+		attribute.String("client.address", testClientIP),
+		attribute.Int("item_count", 3),
+	}
+
+	redacted := redactor.Attributes(input)
+
+	require.Len(t, redacted, 3)
+	assert.Equal(t, "user_id", string(redacted[0].Key))
+	assert.True(t, strings.HasPrefix(redacted[0].Value.AsString(), "sha256:"))
+	assert.Equal(t, redacted[0], redactor.Attributes(input[:1])[0], "hashing must be stable")
+	assert.Equal(t, attribute.String("user_agent.original", "Mozilla"), redacted[1])
+	assert.Equal(t, attribute.Int("item_count", 3), redacted[2])
+	assert.Equal(t, attribute.String("user_id", testUserID), input[0], "input must not be modified")
+}
+
+func TestRedactingSpanProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(NewRedactingSpanProcessor(recorder, newTestRedactor(t))),
+	)
+	defer tracerProvider.Shutdown(context.Background())
+
+	_, span := tracerProvider.Tracer("test").Start(context.Background(), "cart",
+		trace.WithAttributes(attribute.String("user_id", testUserID)))
+	// Attributes set after start must be redacted too
+	span.SetAttributes(attribute.String("client.address", testClientIP))
+	span.AddEvent("request.received", trace.WithAttributes(attribute.String("user_agent.original", testUserAgent)))
+	span.End()
+
+	require.Len(t, recorder.Ended(), 1)
+	ended := recorder.Ended()[0]
+
+	var output strings.Builder
+	for _, attr := range ended.Attributes() {
+		output.WriteString(attr.Value.Emit())
+	}
+	for _, event := range ended.Events() {
+		for _, attr := range event.Attributes {
+			output.WriteString(attr.Value.Emit())
+		}
+	}
+	assertNoSensitiveValues(t, output.String())
+	assert.Len(t, ended.Attributes(), 1)
+}
+
+func TestRedactor_StripQuery(t *testing.T) {
+	rules, err := ParseRedactionRules("url.full=strip_query")
+	require.NoError(t, err)
+	redactor, err := NewRedactor(rules, "")
+	require.NoError(t, err)
+
+	value, kept := redactor.Value("url.full", "https://api.example.com/v1/items?token=secret#top")
+	assert.True(t, kept)
+	assert.Equal(t, "https://api.example.com/v1/items", value)
+	value, kept = redactor.Value("url.full", "/v1/items")
+	assert.True(t, kept)
+	assert.Equal(t, "/v1/items", value)
+}
+
+func TestRedactingSpanProcessor_OtelfiberQueryString(t *testing.T) {
+	rules, err := ParseRedactionRules(config.DefaultRedactionRules)
+	require.NoError(t, err)
+	redactor, err := NewRedactor(rules, "")
+	require.NoError(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(NewRedactingSpanProcessor(recorder, redactor)),
+	)
+	defer tracerProvider.Shutdown(context.Background())
+
+	app := fiber.New()
+	app.Use(otelfiber.Middleware(otelfiber.WithTracerProvider(tracerProvider)))
+	app.Get("/items", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/items?token=secret-token", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Len(t, recorder.Ended(), 1)
+	attributes := map[attribute.Key]string{}
+	for _, attr := range recorder.Ended()[0].Attributes() {
+		attributes[attr.Key] = attr.Value.Emit()
+		assert.NotContains(t, attr.Value.Emit(), "secret-token", attr.Key)
+	}
+	assert.Equal(t, "/items", attributes["http.target"], "the path must survive")
+}
+
+func TestRedactingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactingHandler(slog.NewTextHandler(&buf, nil), newTestRedactor(t)))
+
+	logger.With("ip", testClientIP).
+		WithGroup("request").
+		Info("Cart processed", "userId", testUserID, "user_agent", testUserAgent, "client", slog.GroupValue(slog.String("address", testClientIP)))
+
+	assertNoSensitiveValues(t, buf.String())
+	assert.Contains(t, buf.String(), "request.userId=sha256:")
+	assert.Contains(t, buf.String(), "request.user_agent=Mozilla")
+}
+
+func TestMetricsExporter_Redaction(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	telemetryProvider := &DefaultTelemetryProvider{meter: provider.Meter("test"), redactor: newTestRedactor(t)}
+	exporter := telemetryProvider.GetMetricsExporter()
+
+	exporter.RecordCounter(context.Background(), schemas.CartRequestsTotal, 1, []attribute.KeyValue{
+		attribute.String("user_id", testUserID),
+		attribute.String("client.address", testClientIP),
+	})
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+
+	var output strings.Builder
+	for _, attr := range sum.DataPoints[0].Attributes.ToSlice() {
+		output.WriteString(string(attr.Key) + "=" + attr.Value.Emit())
+	}
+	assertNoSensitiveValues(t, output.String())
+	assert.NotContains(t, output.String(), "client.address")
+}
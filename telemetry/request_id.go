@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RequestIDKey is the span attribute carrying the request ID
+const RequestIDKey = "http.request.id"
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID stores the request ID so logs made with ctx carry it
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID, or "" if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// RequestIDHandler adds a request_id attribute from the record context to every log record,
+// unless the logger already carries one, as the request-scoped logger does. Like
+// TraceContextHandler it applies groups itself, so request_id stays a top-level field for
+// loggers derived with WithGroup.
+type RequestIDHandler struct {
+	handler slog.Handler
+	groups  attrGroups
+	// present is set once a top-level request_id was added through WithAttrs
+	present bool
+}
+
+func NewRequestIDHandler(handler slog.Handler) *RequestIDHandler {
+	return &RequestIDHandler{
+		handler: handler,
+	}
+}
+
+func (r *RequestIDHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return r.handler.Enabled(ctx, level)
+}
+
+func (r *RequestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = r.groups.apply(record)
+	if !r.present {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			record.AddAttrs(slog.String(LogKeyRequestID, requestID))
+		}
+	}
+	return r.handler.Handle(ctx, record)
+}
+
+func (r *RequestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if r.groups.empty() {
+		present := r.present
+		for _, attr := range attrs {
+			present = present || attr.Key == LogKeyRequestID
+		}
+		return &RequestIDHandler{
+			handler: r.handler.WithAttrs(attrs),
+			present: present,
+		}
+	}
+
+	// Inside a group the attributes are kept until Handle nests them with the record's own
+	return &RequestIDHandler{
+		handler: r.handler,
+		groups:  r.groups.withAttrs(attrs),
+		present: r.present,
+	}
+}
+
+func (r *RequestIDHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return r
+	}
+	return &RequestIDHandler{
+		handler: r.handler,
+		groups:  r.groups.withGroup(name),
+		present: r.present,
+	}
+}
@@ -0,0 +1,32 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRequestIDHandler(slog.NewTextHandler(&buf, nil))).With("logger", "services.cart")
+
+	logger.InfoContext(ContextWithRequestID(context.Background(), "req-1"), "cart processed", "items", 2)
+	assert.Contains(t, buf.String(), "logger=services.cart items=2 request_id=req-1")
+
+	buf.Reset()
+	logger.Info("no request")
+	assert.NotContains(t, buf.String(), "request_id")
+}
+
+func TestRequestIDHandler_GroupsKeepRequestIDTopLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRequestIDHandler(slog.NewTextHandler(&buf, nil))).
+		WithGroup("cart").With("id", "c1").WithGroup("pricing")
+
+	logger.InfoContext(ContextWithRequestID(context.Background(), "req-1"), "priced", "total", 30)
+	assert.Contains(t, buf.String(), "cart.id=c1 cart.pricing.total=30 request_id=req-1")
+	assert.NotContains(t, buf.String(), "cart.request_id")
+}
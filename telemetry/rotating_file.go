@@ -0,0 +1,339 @@
+package telemetry
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat sorts lexically in time order, so backups can be ordered by name
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileOptions configures a RotatingFile
+type RotatingFileOptions struct {
+	// Path is the file written to; backups are created next to it
+	Path string
+	// MaxSize rotates the file before a write would take it past this many bytes; 0 disables it
+	MaxSize int64
+	// MaxAge rotates the file once it has been written to for this long; 0 disables it
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept; 0 keeps them all
+	MaxBackups int
+	// Compress gzips rotated files
+	Compress bool
+}
+
+// RotatingFile is an io.WriteCloser that rotates its file by size and age. A rotated file is
+// renamed to name-<timestamp>.ext, then compressed and pruned in the background so writes are
+// not held up. Reopen supports external rotation such as logrotate, which moves the file away
+// and expects the writer to create a new one. When the file cannot be opened again, writes
+// fail and retry the open until it succeeds.
+type RotatingFile struct {
+	options RotatingFileOptions
+	now     func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File // nil after a failed open
+	size     int64
+	openedAt time.Time
+
+	mill     chan struct{}
+	millDone chan struct{}
+	closed   bool
+}
+
+// NewRotatingFile opens or creates the file at options.Path for appending
+func NewRotatingFile(options RotatingFileOptions) (*RotatingFile, error) {
+	if options.Path == "" {
+		return nil, errors.New("log file path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(options.Path), 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &RotatingFile{
+		options:  options,
+		now:      time.Now,
+		mill:     make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	go r.runMill()
+	// Finish the work of a previous run that stopped before compressing or pruning
+	r.scheduleMill()
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate moves the current file to a backup and starts a new one
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+	return r.rotate()
+}
+
+// Reopen closes the file and opens the path again, picking up a file created or moved by an
+// external tool; the path is opened even when closing the old file fails
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+	closeErr := r.closeFile()
+	return errors.Join(closeErr, r.open())
+}
+
+// Close closes the file and waits for pending compression and pruning
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	err := r.closeFile()
+	close(r.mill)
+	r.mu.Unlock()
+
+	<-r.millDone
+	return err
+}
+
+// shouldRotate must be called with mu held; an empty file is never rotated, so a record larger
+// than MaxSize still gets written
+func (r *RotatingFile) shouldRotate(pending int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.options.MaxSize > 0 && r.size+pending > r.options.MaxSize {
+		return true
+	}
+	return r.options.MaxAge > 0 && r.now().Sub(r.openedAt) >= r.options.MaxAge
+}
+
+// closeFile must be called with mu held; the file is released even when Close fails
+func (r *RotatingFile) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// open must be called with mu held
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.options.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = r.now()
+	return nil
+}
+
+// rotate must be called with mu held
+func (r *RotatingFile) rotate() error {
+	if err := r.closeFile(); err != nil {
+		// The descriptor is gone either way; carry on with a new file
+		fmt.Fprintf(os.Stderr, "WARNING: log file %s: %v\n", r.options.Path, err)
+	}
+	if err := os.Rename(r.options.Path, r.backupName(r.now())); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// Keep writing to the current file rather than losing records
+		if openErr := r.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.scheduleMill()
+	return nil
+}
+
+// backupName returns a name for a backup rotated at t. Names have millisecond precision, so
+// when one is taken, plain or compressed, the timestamp moves forward until a free one is found
+// rather than overwriting an earlier backup.
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := r.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+		if !fileExists(name) && !fileExists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// nameParts splits the path into its directory, the backup name prefix and the extension
+func (r *RotatingFile) nameParts() (string, string, string) {
+	dir, name := filepath.Split(r.options.Path)
+	ext := filepath.Ext(name)
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// scheduleMill must be called with mu held, before Close
+func (r *RotatingFile) scheduleMill() {
+	select {
+	case r.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (r *RotatingFile) runMill() {
+	defer close(r.millDone)
+	for range r.mill {
+		if err := r.millBackups(); err != nil {
+			// The file sink may be the only one, so the error cannot go through slog
+			fmt.Fprintf(os.Stderr, "WARNING: log file %s: %v\n", r.options.Path, err)
+		}
+	}
+}
+
+// millBackups compresses rotated files and removes the ones past MaxBackups
+func (r *RotatingFile) millBackups() error {
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if r.options.MaxBackups > 0 && len(backups) > r.options.MaxBackups {
+		for _, backup := range backups[r.options.MaxBackups:] {
+			if err := os.Remove(backup); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		backups = backups[:r.options.MaxBackups]
+	}
+
+	if r.options.Compress {
+		for _, backup := range backups {
+			if strings.HasSuffix(backup, ".gz") {
+				continue
+			}
+			if err := compressFile(backup); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backups lists the rotated files, newest first
+func (r *RotatingFile) backups() ([]string, error) {
+	dir, prefix, ext := r.nameParts()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, ok := strings.CutPrefix(name, prefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, _ = strings.CutSuffix(stamp, ".gz")
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
+
+// compressFile gzips a file to name.gz and removes the original
+func compressFile(name string) (err error) {
+	source, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	// Write to a temporary name so a crash never leaves a truncated .gz behind
+	target, err := os.OpenFile(name+".gz.tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			target.Close()
+			os.Remove(target.Name())
+		}
+	}()
+
+	gz := gzip.NewWriter(target)
+	if _, err = io.Copy(gz, source); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = target.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(target.Name(), name+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,176 @@
+package telemetry
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRotatingFile(t *testing.T, options RotatingFileOptions) (*RotatingFile, *time.Time) {
+	t.Helper()
+	options.Path = filepath.Join(t.TempDir(), "app.log")
+	file, err := NewRotatingFile(options)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	file.now = func() time.Time { return now }
+	file.openedAt = now
+	return file, &now
+}
+
+// listLogFiles returns the names in the file's directory, sorted
+func listLogFiles(t *testing.T, file *RotatingFile) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Dir(file.options.Path))
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readLogFile(t *testing.T, name string) string {
+	t.Helper()
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var reader io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		reader = gz
+	}
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	file, now := newTestRotatingFile(t, RotatingFileOptions{MaxSize: 10})
+
+	_, err := file.Write([]byte("first\n"))
+	require.NoError(t, err)
+	*now = now.Add(time.Second)
+	_, err = file.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, []string{"app-2026-01-01T00-00-01.000.log", "app.log"}, listLogFiles(t, file))
+	dir := filepath.Dir(file.options.Path)
+	assert.Equal(t, "first\n", readLogFile(t, filepath.Join(dir, "app-2026-01-01T00-00-01.000.log")))
+	assert.Equal(t, "second\n", readLogFile(t, file.options.Path))
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	file, now := newTestRotatingFile(t, RotatingFileOptions{MaxAge: time.Hour})
+
+	_, err := file.Write([]byte("old\n"))
+	require.NoError(t, err)
+	*now = now.Add(30 * time.Minute)
+	_, err = file.Write([]byte("still old\n"))
+	require.NoError(t, err)
+	*now = now.Add(30 * time.Minute)
+	_, err = file.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Len(t, listLogFiles(t, file), 2)
+	assert.Equal(t, "new\n", readLogFile(t, file.options.Path))
+}
+
+func TestRotatingFile_CompressesAndPrunesBackups(t *testing.T) {
+	file, now := newTestRotatingFile(t, RotatingFileOptions{MaxBackups: 2, Compress: true})
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+		*now = now.Add(time.Second)
+		require.NoError(t, file.Rotate())
+	}
+	require.NoError(t, file.Close())
+
+	names := listLogFiles(t, file)
+	assert.Equal(t, []string{
+		"app-2026-01-01T00-00-03.000.log.gz",
+		"app-2026-01-01T00-00-04.000.log.gz",
+		"app.log",
+	}, names)
+	dir := filepath.Dir(file.options.Path)
+	assert.Equal(t, "four\n", readLogFile(t, filepath.Join(dir, names[1])))
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	file, _ := newTestRotatingFile(t, RotatingFileOptions{})
+
+	_, err := file.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	// logrotate moves the file away, then signals the process to reopen it
+	moved := file.options.Path + ".1"
+	require.NoError(t, os.Rename(file.options.Path, moved))
+	require.NoError(t, file.Reopen())
+
+	_, err = file.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, "before\n", readLogFile(t, moved))
+	assert.Equal(t, "after\n", readLogFile(t, file.options.Path))
+
+	_, err = file.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestRotatingFile_BackupsInSameMillisecondAreKept(t *testing.T) {
+	file, _ := newTestRotatingFile(t, RotatingFileOptions{})
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+		require.NoError(t, file.Rotate())
+	}
+	require.NoError(t, file.Close())
+
+	names := listLogFiles(t, file)
+	assert.Equal(t, []string{
+		"app-2026-01-01T00-00-00.000.log",
+		"app-2026-01-01T00-00-00.001.log",
+		"app-2026-01-01T00-00-00.002.log",
+		"app.log",
+	}, names)
+	dir := filepath.Dir(file.options.Path)
+	assert.Equal(t, "three\n", readLogFile(t, filepath.Join(dir, names[2])))
+}
+
+func TestRotatingFile_RecoversFromFailedOpen(t *testing.T) {
+	file, _ := newTestRotatingFile(t, RotatingFileOptions{})
+	dir := filepath.Dir(file.options.Path)
+
+	_, err := file.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	// With the directory gone, the new file cannot be created
+	require.NoError(t, os.RemoveAll(dir))
+	require.Error(t, file.Rotate())
+	_, err = file.Write([]byte("lost\n"))
+	require.Error(t, err)
+	require.Error(t, file.Reopen())
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	_, err = file.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Equal(t, "after\n", readLogFile(t, file.options.Path))
+}
@@ -0,0 +1,207 @@
+package telemetry
+
+import (
+	"context"
+	"fiber-api/schemas"
+	"math"
+	"runtime/metrics"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// runtime/metrics keys sampled on every collection
+const (
+	goroutinesMetric     = "/sched/goroutines:goroutines"
+	gcPausesMetric       = "/sched/pauses/total/gc:seconds"
+	gcCyclesMetric       = "/gc/cycles/total:gc-cycles"
+	heapObjectsMetric    = "/memory/classes/heap/objects:bytes"
+	heapAllocsMetric     = "/gc/heap/allocs:bytes"
+	schedLatenciesMetric = "/sched/latencies:seconds"
+)
+
+// Quantiles reported for the runtime histograms, which observable instruments cannot carry directly
+var runtimeQuantiles = []float64{0.5, 0.9, 0.99, 1}
+
+// histogramDelta turns the cumulative runtime histograms into the distribution of the events
+// since the previous collection, so the quantiles follow current behaviour instead of being
+// dominated by the whole life of the process
+type histogramDelta struct {
+	mu       sync.Mutex
+	previous []uint64
+}
+
+// next returns the histogram of the events since the previous call and remembers h
+func (d *histogramDelta) next(h *metrics.Float64Histogram) *metrics.Float64Histogram {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delta := &metrics.Float64Histogram{Counts: make([]uint64, len(h.Counts)), Buckets: h.Buckets}
+	for i, count := range h.Counts {
+		delta.Counts[i] = count
+		if len(d.previous) == len(h.Counts) {
+			delta.Counts[i] -= d.previous[i]
+		}
+	}
+	d.previous = append(d.previous[:0], h.Counts...)
+	return delta
+}
+
+// RegisterRuntimeMetrics registers Go runtime and process observable instruments on the given meter
+func RegisterRuntimeMetrics(meter metric.Meter) (metric.Registration, error) {
+	goroutines, err := meter.Int64ObservableGauge(schemas.RuntimeGoroutines,
+		metric.WithDescription("Number of live goroutines"),
+		metric.WithUnit("{goroutine}"))
+	if err != nil {
+		return nil, err
+	}
+
+	gcPause, err := meter.Float64ObservableGauge(schemas.RuntimeGCPauseSeconds,
+		metric.WithDescription("Distribution of GC stop-the-world pause latencies"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	gcCycles, err := meter.Int64ObservableCounter(schemas.RuntimeGCCyclesTotal,
+		metric.WithDescription("Number of completed GC cycles"),
+		metric.WithUnit("{gc_cycle}"))
+	if err != nil {
+		return nil, err
+	}
+
+	heapInUse, err := meter.Int64ObservableGauge(schemas.RuntimeHeapInUseBytes,
+		metric.WithDescription("Heap memory occupied by live and not-yet-swept objects"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+
+	heapAlloc, err := meter.Int64ObservableCounter(schemas.RuntimeHeapAllocBytesTotal,
+		metric.WithDescription("Cumulative bytes allocated on the heap"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+
+	schedLatency, err := meter.Float64ObservableGauge(schemas.RuntimeSchedLatencySeconds,
+		metric.WithDescription("Distribution of time goroutines spent runnable before running"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	openFDs, err := meter.Int64ObservableGauge(schemas.ProcessOpenFileDescriptors,
+		metric.WithDescription("Number of open file descriptors"),
+		metric.WithUnit("{file_descriptor}"))
+	if err != nil {
+		return nil, err
+	}
+
+	cpuTime, err := meter.Float64ObservableCounter(schemas.ProcessCPUTimeSecondsTotal,
+		metric.WithDescription("Total user and system CPU time consumed by the process"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	var gcPauseDelta, schedLatencyDelta histogramDelta
+	samples := []metrics.Sample{
+		{Name: goroutinesMetric},
+		{Name: gcPausesMetric},
+		{Name: gcCyclesMetric},
+		{Name: heapObjectsMetric},
+		{Name: heapAllocsMetric},
+		{Name: schedLatenciesMetric},
+	}
+
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		metrics.Read(samples)
+
+		for _, sample := range samples {
+			switch sample.Name {
+			case goroutinesMetric:
+				o.ObserveInt64(goroutines, int64(sampleUint64(sample)))
+			case gcCyclesMetric:
+				o.ObserveInt64(gcCycles, int64(sampleUint64(sample)))
+			case heapObjectsMetric:
+				o.ObserveInt64(heapInUse, int64(sampleUint64(sample)))
+			case heapAllocsMetric:
+				o.ObserveInt64(heapAlloc, int64(sampleUint64(sample)))
+			case gcPausesMetric:
+				observeQuantiles(o, gcPause, &gcPauseDelta, sample)
+			case schedLatenciesMetric:
+				observeQuantiles(o, schedLatency, &schedLatencyDelta, sample)
+			}
+		}
+
+		if stats, ok := readProcessStats(); ok {
+			if stats.openFDs >= 0 {
+				o.ObserveInt64(openFDs, stats.openFDs)
+			}
+			o.ObserveFloat64(cpuTime, stats.userCPUSeconds, metric.WithAttributes(attribute.String("state", "user")))
+			o.ObserveFloat64(cpuTime, stats.systemCPUSeconds, metric.WithAttributes(attribute.String("state", "system")))
+		}
+
+		return nil
+	}, goroutines, gcPause, gcCycles, heapInUse, heapAlloc, schedLatency, openFDs, cpuTime)
+}
+
+func sampleUint64(sample metrics.Sample) uint64 {
+	if sample.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample.Value.Uint64()
+}
+
+// observeQuantiles reports the quantiles of the events since the previous collection; an interval
+// without events is not observed, rather than reported as zero latency
+func observeQuantiles(o metric.Observer, gauge metric.Float64ObservableGauge, delta *histogramDelta, sample metrics.Sample) {
+	if sample.Value.Kind() != metrics.KindFloat64Histogram {
+		return
+	}
+	histogram := delta.next(sample.Value.Float64Histogram())
+	if histogramTotal(histogram) == 0 {
+		return
+	}
+	for _, q := range runtimeQuantiles {
+		o.ObserveFloat64(gauge, histogramQuantile(histogram, q),
+			metric.WithAttributes(attribute.Float64("quantile", q)))
+	}
+}
+
+// histogramQuantile returns the upper bound of the bucket holding the q-th quantile
+func histogramQuantile(h *metrics.Float64Histogram, q float64) float64 {
+	total := histogramTotal(h)
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var cumulative uint64
+	for i, count := range h.Counts {
+		cumulative += count
+		if cumulative >= rank {
+			upper := h.Buckets[i+1]
+			if math.IsInf(upper, 1) {
+				// The last bucket is unbounded, so fall back to its lower bound
+				return h.Buckets[i]
+			}
+			return upper
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+func histogramTotal(h *metrics.Float64Histogram) uint64 {
+	var total uint64
+	for _, count := range h.Counts {
+		total += count
+	}
+	return total
+}
@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"context"
+	"fiber-api/schemas"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterRuntimeMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	_, err := RegisterRuntimeMetrics(provider.Meter("test"))
+	require.NoError(t, err)
+	// Quantiles cover the events since the previous collection, so make sure there is a GC pause
+	runtime.GC()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	collected := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			collected[m.Name] = m.Data
+		}
+	}
+
+	for _, name := range []string{
+		schemas.RuntimeGoroutines,
+		schemas.RuntimeGCPauseSeconds,
+		schemas.RuntimeGCCyclesTotal,
+		schemas.RuntimeHeapInUseBytes,
+		schemas.RuntimeHeapAllocBytesTotal,
+		schemas.RuntimeSchedLatencySeconds,
+	} {
+		assert.Contains(t, collected, name)
+	}
+
+	goroutines, ok := collected[schemas.RuntimeGoroutines].(metricdata.Gauge[int64])
+	require.True(t, ok)
+	require.Len(t, goroutines.DataPoints, 1)
+	assert.Positive(t, goroutines.DataPoints[0].Value)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{5, 3, 2},
+		Buckets: []float64{0, 1, 2, math.Inf(1)},
+	}
+
+	assert.Equal(t, 1.0, histogramQuantile(h, 0.5))
+	assert.Equal(t, 2.0, histogramQuantile(h, 0.8))
+	assert.Equal(t, 2.0, histogramQuantile(h, 1))
+	assert.Equal(t, 0.0, histogramQuantile(&metrics.Float64Histogram{Counts: []uint64{0}, Buckets: []float64{0, 1}}, 0.5))
+}
+
+func TestHistogramDelta(t *testing.T) {
+	var delta histogramDelta
+	buckets := []float64{0, 1, 2, math.Inf(1)}
+
+	first := delta.next(&metrics.Float64Histogram{Counts: []uint64{5, 3, 0}, Buckets: buckets})
+	assert.Equal(t, []uint64{5, 3, 0}, first.Counts, "the first collection covers the process so far")
+
+	// Only the new, slower events count towards the next quantiles
+	second := delta.next(&metrics.Float64Histogram{Counts: []uint64{5, 3, 4}, Buckets: buckets})
+	assert.Equal(t, []uint64{0, 0, 4}, second.Counts)
+	assert.Equal(t, 2.0, histogramQuantile(second, 0.5))
+
+	third := delta.next(&metrics.Float64Histogram{Counts: []uint64{5, 3, 4}, Buckets: buckets})
+	assert.Zero(t, histogramTotal(third))
+}
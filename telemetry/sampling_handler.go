@@ -0,0 +1,203 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplingOptions configures how repeated log records are thinned out
+type SamplingOptions struct {
+	// Window is the period over which identical records are counted
+	Window time.Duration
+	// First is the number of identical records kept in each window
+	First int
+	// Thereafter keeps every Mth identical record after the first N; 0 drops them all
+	Thereafter int
+	// KeepSampledTraces exempts records on sampled traces. With the default parent-based
+	// always-on sampler every request trace is sampled, so this disables sampling for requests.
+	KeepSampledTraces bool
+}
+
+// samplingKey identifies identical records
+type samplingKey struct {
+	level   slog.Level
+	message string
+}
+
+type samplingCounter struct {
+	seen       int
+	suppressed int
+}
+
+// samplingState is shared by every handler derived from one SamplingHandler, so a message is
+// counted once however many loggers emit it
+type samplingState struct {
+	mu          sync.Mutex
+	root        slog.Handler
+	windowStart time.Time
+	counters    map[samplingKey]*samplingCounter
+	now         func() time.Time
+	// flushTimer writes the summaries of a window that suppressed records once it closes, in
+	// case no record arrives after it; it is only armed while there is something to report
+	flushTimer *time.Timer
+}
+
+// SamplingHandler deduplicates log storms: within each window it keeps the first N records
+// with the same level and message, then every Mth. Records on sampled traces can optionally be
+// exempted, so a trace never loses its logs. When a window closes, each message that lost
+// records gets a "suppressed N messages" summary; the summary is written by the next record
+// after the window or, failing one, by a timer at the end of the window, or by Flush.
+type SamplingHandler struct {
+	handler slog.Handler
+	options SamplingOptions
+	state   *samplingState
+}
+
+func NewSamplingHandler(handler slog.Handler, options SamplingOptions) *SamplingHandler {
+	return &SamplingHandler{
+		handler: handler,
+		options: options,
+		state: &samplingState{
+			root:     handler,
+			counters: make(map[samplingKey]*samplingCounter),
+			now:      time.Now,
+		},
+	}
+}
+
+func (s *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.handler.Enabled(ctx, level)
+}
+
+func (s *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	keep, summaries := s.sample(ctx, record)
+	// Summaries cover many requests, so they carry none of this record's trace context
+	for _, summary := range summaries {
+		if err := s.state.root.Handle(context.Background(), summary); err != nil {
+			return err
+		}
+	}
+	if !keep {
+		return nil
+	}
+	return s.handler.Handle(ctx, record)
+}
+
+// sample decides whether to keep the record and returns the summaries of a closed window
+func (s *SamplingHandler) sample(ctx context.Context, record slog.Record) (bool, []slog.Record) {
+	state := s.state
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	var summaries []slog.Record
+	now := state.now()
+	if now.Sub(state.windowStart) >= s.options.Window {
+		summaries = s.closeWindow(now)
+		state.windowStart = now
+	}
+
+	key := samplingKey{level: record.Level, message: record.Message}
+	counter, ok := state.counters[key]
+	if !ok {
+		counter = &samplingCounter{}
+		state.counters[key] = counter
+	}
+	counter.seen++
+
+	if counter.seen <= s.options.First {
+		return true, summaries
+	}
+	if s.options.KeepSampledTraces && trace.SpanContextFromContext(ctx).IsSampled() {
+		return true, summaries
+	}
+	if s.options.Thereafter > 0 && (counter.seen-s.options.First)%s.options.Thereafter == 0 {
+		return true, summaries
+	}
+	counter.suppressed++
+	if state.flushTimer == nil {
+		state.flushTimer = time.AfterFunc(s.options.Window-now.Sub(state.windowStart), s.flushExpired)
+	}
+	return false, summaries
+}
+
+// flushExpired writes the summaries of the current window once it has closed
+func (s *SamplingHandler) flushExpired() {
+	state := s.state
+	state.mu.Lock()
+	now := state.now()
+	// The timer of a window a record has already closed finds the next window still open
+	if now.Sub(state.windowStart) < s.options.Window {
+		state.mu.Unlock()
+		return
+	}
+	summaries := s.closeWindow(now)
+	state.windowStart = now
+	state.mu.Unlock()
+
+	for _, summary := range summaries {
+		if err := state.root.Handle(context.Background(), summary); err != nil {
+			return
+		}
+	}
+}
+
+// closeWindow resets the counters and builds a summary for every message that lost records;
+// it must be called with the state lock held
+func (s *SamplingHandler) closeWindow(now time.Time) []slog.Record {
+	state := s.state
+	if state.flushTimer != nil {
+		state.flushTimer.Stop()
+		state.flushTimer = nil
+	}
+
+	var summaries []slog.Record
+	for key, counter := range state.counters {
+		if counter.suppressed == 0 {
+			continue
+		}
+		summary := slog.NewRecord(now, key.level, "suppressed "+strconv.Itoa(counter.suppressed)+" messages", 0)
+		summary.AddAttrs(
+			slog.String("suppressed_message", key.message),
+			slog.Int("suppressed_count", counter.suppressed),
+			slog.Duration("window", s.options.Window),
+		)
+		summaries = append(summaries, summary)
+	}
+	clear(state.counters)
+	return summaries
+}
+
+// Flush writes the summaries of the current window and stops its timer, e.g. before shutdown
+func (s *SamplingHandler) Flush(ctx context.Context) error {
+	s.state.mu.Lock()
+	summaries := s.closeWindow(s.state.now())
+	s.state.mu.Unlock()
+
+	for _, summary := range summaries {
+		if err := s.state.root.Handle(ctx, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{
+		handler: s.handler.WithAttrs(attrs),
+		options: s.options,
+		state:   s.state,
+	}
+}
+
+func (s *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{
+		handler: s.handler.WithGroup(name),
+		options: s.options,
+		state:   s.state,
+	}
+}
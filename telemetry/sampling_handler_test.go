@@ -0,0 +1,107 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func newTestSamplingLogger(options SamplingOptions) (*slog.Logger, *SamplingHandler, *bytes.Buffer, *time.Time) {
+	var buf bytes.Buffer
+	handler := NewSamplingHandler(slog.NewTextHandler(&buf, nil), options)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler.state.now = func() time.Time { return now }
+	return slog.New(handler), handler, &buf, &now
+}
+
+func TestSamplingHandler_FirstThenEveryMth(t *testing.T) {
+	logger, _, buf, _ := newTestSamplingLogger(SamplingOptions{Window: time.Second, First: 2, Thereafter: 3})
+
+	for i := range 9 {
+		logger.Error("database unavailable", "attempt", i+1)
+	}
+	logger.Error("other failure")
+
+	// Records 1 and 2 pass, then every third of the rest: 5 and 8
+	output := buf.String()
+	for _, attempt := range []string{"attempt=1\n", "attempt=2\n", "attempt=5\n", "attempt=8\n"} {
+		assert.Contains(t, output, attempt)
+	}
+	assert.Equal(t, 4, strings.Count(output, "database unavailable"))
+	assert.Contains(t, output, "other failure")
+}
+
+func TestSamplingHandler_SummaryAfterWindow(t *testing.T) {
+	logger, handler, buf, now := newTestSamplingLogger(SamplingOptions{Window: time.Second, First: 1})
+
+	for range 5 {
+		logger.Warn("slow request")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "slow request"))
+
+	// The next record after the window reports what the previous window suppressed
+	*now = now.Add(time.Second)
+	logger.With("derived", true).Warn("slow request")
+
+	output := buf.String()
+	assert.Contains(t, output, `msg="suppressed 4 messages"`)
+	assert.Contains(t, output, `suppressed_message="slow request"`)
+	assert.Contains(t, output, "suppressed_count=4")
+	assert.Equal(t, 3, strings.Count(output, "slow request"))
+
+	// Flush reports the current window without waiting for another record
+	buf.Reset()
+	logger.Warn("slow request")
+	require.NoError(t, handler.Flush(context.Background()))
+	assert.Contains(t, buf.String(), "suppressed 1 messages")
+}
+
+func TestSamplingHandler_SummaryWithoutLaterRecord(t *testing.T) {
+	root := &recordingHandler{}
+	handler := NewSamplingHandler(root, SamplingOptions{Window: 20 * time.Millisecond, First: 1})
+	logger := slog.New(handler)
+	t.Cleanup(func() { handler.Flush(context.Background()) })
+
+	for range 3 {
+		logger.Warn("slow request")
+	}
+
+	// Nothing is logged after the storm, so the window's timer writes its summary
+	assert.Eventually(t, func() bool {
+		return slices.Contains(root.Messages(), "suppressed 2 messages")
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"slow request", "suppressed 2 messages"}, root.Messages())
+}
+
+func TestSamplingHandler_SampledTraces(t *testing.T) {
+	// Spans of the SDK's default parent-based always-on sampler, as the provider uses
+	tracerProvider := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+	require.True(t, span.SpanContext().IsSampled())
+
+	// By default a storm inside sampled requests is thinned like any other
+	logger, _, buf, _ := newTestSamplingLogger(SamplingOptions{Window: time.Second, First: 1})
+	for range 3 {
+		logger.ErrorContext(ctx, "failure")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "msg=failure"))
+
+	logger, _, buf, _ = newTestSamplingLogger(SamplingOptions{Window: time.Second, First: 1, KeepSampledTraces: true})
+	for range 3 {
+		logger.ErrorContext(context.Background(), "failure")
+	}
+	for range 3 {
+		logger.ErrorContext(ctx, "failure")
+	}
+	assert.Equal(t, 4, strings.Count(buf.String(), "msg=failure"))
+}
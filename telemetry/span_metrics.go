@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"context"
+	"fiber-api/schemas"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span metric attribute keys; span names must stay low-cardinality for these series to aggregate
+const (
+	spanNameKey   = attribute.Key("span.name")
+	spanKindKey   = attribute.Key("span.kind")
+	spanStatusKey = attribute.Key("status.code")
+)
+
+// SpanMetricsProcessor derives RED metrics (rate, errors, duration) from every ended span,
+// grouped by span name, kind and status, so new spans need no hand-written metric calls
+type SpanMetricsProcessor struct {
+	calls    metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func NewSpanMetricsProcessor(meter metric.Meter) (*SpanMetricsProcessor, error) {
+	calls, err := meter.Int64Counter(schemas.SpanCallsTotal,
+		metric.WithDescription("Number of ended spans"),
+		metric.WithUnit("{span}"))
+	if err != nil {
+		return nil, err
+	}
+
+	errors, err := meter.Int64Counter(schemas.SpanErrorsTotal,
+		metric.WithDescription("Number of ended spans with an error status"),
+		metric.WithUnit("{span}"))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram(schemas.SpanDurationSeconds,
+		metric.WithDescription("Duration of ended spans"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpanMetricsProcessor{
+		calls:    calls,
+		errors:   errors,
+		duration: duration,
+	}, nil
+}
+
+func (p *SpanMetricsProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+func (p *SpanMetricsProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	status := s.Status().Code
+	attributes := metric.WithAttributes(
+		spanNameKey.String(s.Name()),
+		spanKindKey.String(s.SpanKind().String()),
+		spanStatusKey.String(strings.ToLower(status.String())),
+	)
+
+	// Carry the span context so trace-based exemplars link the measurement to the span
+	ctx := trace.ContextWithSpanContext(context.Background(), s.SpanContext())
+
+	p.calls.Add(ctx, 1, attributes)
+	if status == codes.Error {
+		p.errors.Add(ctx, 1, attributes)
+	}
+	p.duration.Record(ctx, s.EndTime().Sub(s.StartTime()).Seconds(), attributes)
+}
+
+func (p *SpanMetricsProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (p *SpanMetricsProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
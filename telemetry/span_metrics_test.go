@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fiber-api/schemas"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanMetricsProcessor(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer meterProvider.Shutdown(context.Background())
+
+	processor, err := NewSpanMetricsProcessor(meterProvider.Meter("test"))
+	require.NoError(t, err)
+
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	defer tracerProvider.Shutdown(context.Background())
+
+	// Spans started through the TracesExporter get metrics without any explicit recording
+	exporter := NewTracesExporter(tracerProvider.Tracer("test"))
+	for range 2 {
+		_, span := exporter.StartSpan(context.Background(), "cart.pricing")
+		span.End()
+	}
+	_, span := exporter.StartSpan(context.Background(), "cart.persist", WithSpanKind(trace.SpanKindClient))
+	span.RecordError(errors.New("store unavailable"))
+	span.SetStatus(codes.Error, "store unavailable")
+	span.End()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	pricing := attribute.NewSet(
+		spanNameKey.String("cart.pricing"),
+		spanKindKey.String("internal"),
+		spanStatusKey.String("unset"),
+	)
+	persist := attribute.NewSet(
+		spanNameKey.String("cart.persist"),
+		spanKindKey.String("client"),
+		spanStatusKey.String("error"),
+	)
+
+	calls, ok := metrics[schemas.SpanCallsTotal].(metricdata.Sum[int64])
+	require.True(t, ok)
+	counts := map[attribute.Distinct]int64{}
+	for _, dp := range calls.DataPoints {
+		counts[dp.Attributes.Equivalent()] = dp.Value
+	}
+	assert.Equal(t, int64(2), counts[pricing.Equivalent()])
+	assert.Equal(t, int64(1), counts[persist.Equivalent()])
+
+	errorsTotal, ok := metrics[schemas.SpanErrorsTotal].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, errorsTotal.DataPoints, 1)
+	assert.True(t, errorsTotal.DataPoints[0].Attributes.Equals(&persist))
+
+	duration, ok := metrics[schemas.SpanDurationSeconds].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Len(t, duration.DataPoints, 2)
+}
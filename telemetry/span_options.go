@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanOption configures a span started through TracesExporter.StartSpan
+type SpanOption func(*spanConfig)
+
+type spanConfig struct {
+	kind       trace.SpanKind
+	attributes []attribute.KeyValue
+	links      []trace.Link
+	startTime  time.Time
+}
+
+func newSpanConfig(opts []SpanOption) spanConfig {
+	cfg := spanConfig{kind: trace.SpanKindInternal}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// startOptions converts the config into OpenTelemetry span start options
+func (c spanConfig) startOptions() []trace.SpanStartOption {
+	opts := []trace.SpanStartOption{trace.WithSpanKind(c.kind)}
+	if len(c.attributes) > 0 {
+		opts = append(opts, trace.WithAttributes(c.attributes...))
+	}
+	if len(c.links) > 0 {
+		opts = append(opts, trace.WithLinks(c.links...))
+	}
+	if !c.startTime.IsZero() {
+		opts = append(opts, trace.WithTimestamp(c.startTime))
+	}
+	return opts
+}
+
+// WithSpanKind sets the span kind, which defaults to internal
+func WithSpanKind(kind trace.SpanKind) SpanOption {
+	return func(c *spanConfig) {
+		c.kind = kind
+	}
+}
+
+// WithAttributes sets attributes on the span at start so samplers can see them
+func WithAttributes(attributes ...attribute.KeyValue) SpanOption {
+	return func(c *spanConfig) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+// WithLinks links the span to other spans, such as the producer of a queued message
+func WithLinks(links ...trace.Link) SpanOption {
+	return func(c *spanConfig) {
+		c.links = append(c.links, links...)
+	}
+}
+
+// WithStartTime backdates the span start, for work measured before the span existed
+func WithStartTime(startTime time.Time) SpanOption {
+	return func(c *spanConfig) {
+		c.startTime = startTime
+	}
+}
@@ -1,7 +1,18 @@
 package telemetry
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestTelemetryProvider_Setup(t *testing.T) {
@@ -30,8 +41,42 @@ func TestLogger_LogError(t *testing.T) {
 }
 
 func TestTracesExporter_StartSpan(t *testing.T) {
-	// Skip this test as it requires external connections
-	t.Skip("Skipping traces test to avoid external connections")
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer tracerProvider.Shutdown(context.Background())
+	exporter := NewTracesExporter(tracerProvider.Tracer("test"))
+
+	_, linked := exporter.StartSpan(context.Background(), "linked")
+	linked.End()
+
+	start := time.Now().Add(-time.Second)
+	ctx, span := exporter.StartSpan(context.Background(), "outbound",
+		WithSpanKind(trace.SpanKindClient),
+		WithAttributes(attribute.String("peer.service", "inventory")),
+		WithLinks(trace.Link{SpanContext: linked.SpanContext()}),
+		WithStartTime(start),
+	)
+	assert.True(t, span.SpanContext().IsValid())
+
+	exporter.SetAttributes(ctx, []attribute.KeyValue{attribute.Int("retry", 1)})
+	exporter.RecordError(ctx, errors.New("timeout"), nil)
+	exporter.SetStatus(ctx, codes.Error, "timeout")
+	span.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	recorded := ended[1]
+
+	assert.Equal(t, "outbound", recorded.Name())
+	assert.Equal(t, trace.SpanKindClient, recorded.SpanKind())
+	assert.Equal(t, start, recorded.StartTime())
+	assert.Contains(t, recorded.Attributes(), attribute.String("peer.service", "inventory"))
+	assert.Contains(t, recorded.Attributes(), attribute.Int("retry", 1))
+	require.Len(t, recorded.Links(), 1)
+	assert.Equal(t, linked.SpanContext().SpanID(), recorded.Links()[0].SpanContext.SpanID())
+	assert.Equal(t, codes.Error, recorded.Status().Code)
+	require.Len(t, recorded.Events(), 1)
+	assert.Equal(t, "exception", recorded.Events()[0].Name)
 }
 
 func TestHTTPHandlerTelemetry(t *testing.T) {
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDFromContext returns the hex trace ID of the span in ctx, or "" without a valid span
+func TraceIDFromContext(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// TraceContextHandler adds trace_id and span_id attributes from the record context, which the
+// OpenTelemetry bridge records natively but plain slog handlers such as the console do not.
+//
+// Groups are applied here rather than in the wrapped handler, by nesting attributes, so the
+// trace attributes stay top-level fields even for loggers derived with WithGroup. A trace_id
+// the logger already carries, as the request-scoped logger does, is not repeated.
+type TraceContextHandler struct {
+	handler slog.Handler
+	groups  attrGroups
+	// hasTraceID is set once a top-level trace_id was added through WithAttrs
+	hasTraceID bool
+}
+
+func NewTraceContextHandler(handler slog.Handler) *TraceContextHandler {
+	return &TraceContextHandler{
+		handler: handler,
+	}
+}
+
+func (t *TraceContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return t.handler.Enabled(ctx, level)
+}
+
+func (t *TraceContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = t.groups.apply(record)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		if !t.hasTraceID {
+			record.AddAttrs(slog.String(LogKeyTraceID, spanContext.TraceID().String()))
+		}
+		record.AddAttrs(slog.String(LogKeySpanID, spanContext.SpanID().String()))
+	}
+	return t.handler.Handle(ctx, record)
+}
+
+func (t *TraceContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if t.groups.empty() {
+		hasTraceID := t.hasTraceID
+		for _, attr := range attrs {
+			hasTraceID = hasTraceID || attr.Key == LogKeyTraceID
+		}
+		return &TraceContextHandler{
+			handler:    t.handler.WithAttrs(attrs),
+			hasTraceID: hasTraceID,
+		}
+	}
+
+	// Inside a group the attributes are kept until Handle nests them with the record's own
+	return &TraceContextHandler{
+		handler:    t.handler,
+		groups:     t.groups.withAttrs(attrs),
+		hasTraceID: t.hasTraceID,
+	}
+}
+
+func (t *TraceContextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return t
+	}
+	return &TraceContextHandler{
+		handler:    t.handler,
+		groups:     t.groups.withGroup(name),
+		hasTraceID: t.hasTraceID,
+	}
+}
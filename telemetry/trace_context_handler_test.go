@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewTraceContextHandler(slog.NewTextHandler(&buf, nil)))
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x0a},
+		SpanID:  trace.SpanID{0x0b},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	logger.InfoContext(ctx, "with span")
+	assert.Contains(t, buf.String(), "trace_id="+spanContext.TraceID().String())
+	assert.Contains(t, buf.String(), "span_id="+spanContext.SpanID().String())
+	assert.Equal(t, spanContext.TraceID().String(), TraceIDFromContext(ctx))
+
+	buf.Reset()
+	logger.InfoContext(context.Background(), "without span")
+	assert.NotContains(t, buf.String(), "trace_id")
+	assert.Empty(t, TraceIDFromContext(context.Background()))
+}
+
+func TestTraceContextHandler_GroupsKeepTraceFieldsTopLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewTraceContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x0a},
+		SpanID:  trace.SpanID{0x0b},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	logger.WithGroup("request").With("method", "GET").WithGroup("cart").InfoContext(ctx, "grouped", "items", 2)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, spanContext.TraceID().String(), entry["trace_id"])
+	assert.Equal(t, map[string]any{
+		"method": "GET",
+		"cart":   map[string]any{"items": float64(2)},
+	}, entry["request"])
+}